@@ -14,6 +14,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/dgraph-io/dgo/v250"
+	"github.com/dgraph-io/dgo/v250/admin"
+	"github.com/dgraph-io/dgo/v250/graphql"
 	"github.com/dgraph-io/dgo/v250/protos/api"
 )
 
@@ -48,122 +50,21 @@ func initializeDBACLs(t *testing.T, dg *dgo.Dgraph) {
 	require.NoError(t, err, "unable to insert data for read predicate")
 }
 
-func resetUser(t *testing.T, token *HttpToken) {
-	resetUser := `mutation addUser($name: String!, $pass: String!) {
-					  addUser(input: [{name: $name, password: $pass}]) {
-						user {
-						  name
-						}
-					  }
-					}`
-
-	params := &GraphQLParams{
-		Query: resetUser,
-		Variables: map[string]interface{}{
-			"name": username,
-			"pass": userpassword,
-		},
-	}
-	resp := MakeGQLRequest(t, adminUrl, params, token)
-	require.True(t, len(resp.Errors) == 0, resp.Errors)
+func resetUser(t *testing.T, ac *admin.Client) {
+	err := ac.AddUser(context.Background(), username, userpassword)
+	require.NoError(t, err, "unable to create user")
 }
 
-func createGroupACLs(t *testing.T, groupname string, token *HttpToken) {
-	// create group
-	createGroup := `mutation addGroup($name: String!){
-			addGroup(input: [{name: $name}]) {
-				group {
-				  name
-				  users {
-					name
-				  }
-				}
-			  }
-			}`
-	params := &GraphQLParams{
-		Query: createGroup,
-		Variables: map[string]interface{}{
-			"name": groupname,
-		},
-	}
-	resp := MakeGQLRequest(t, adminUrl, params, token)
-	require.Truef(t, len(resp.Errors) == 0, "unable to create group: %+v", resp.Errors)
-
-	// Set permissions.
-	updatePerms := `mutation updateGroup($gname: String!, $pred: String!, $perm: Int!) {
-		  updateGroup(input: {filter: {name: {eq: $gname}}, set: {rules: [{predicate: $pred, permission: $perm}]}}) {
-			group {
-			  name
-			  rules {
-				permission
-				predicate
-			  }
-			}
-		  }
-		}`
-
-	setPermission := func(pred string, permission int) {
-		params = &GraphQLParams{
-			Query: updatePerms,
-			Variables: map[string]interface{}{
-				"gname": groupname,
-				"pred":  pred,
-				"perm":  permission,
-			},
-		}
-		resp = MakeGQLRequest(t, adminUrl, params, token)
-		require.Truef(t, len(resp.Errors) == 0, "unable to set permissions: %+v", resp.Errors)
-	}
+func createGroupACLs(t *testing.T, groupname string, ac *admin.Client) {
+	ctx := context.Background()
+	require.NoError(t, ac.AddGroup(ctx, groupname), "unable to create group")
 
 	// assign read access to read predicate
-	setPermission(readpred, 4)
+	require.NoError(t, ac.SetPermission(ctx, groupname, readpred, admin.PermissionRead))
 	// assign write access to write predicate
-	setPermission(writepred, 2)
+	require.NoError(t, ac.SetPermission(ctx, groupname, writepred, admin.PermissionWrite))
 	// assign modify access to modify predicate
-	setPermission(modifypred, 1)
-}
-
-func addUserToGroup(t *testing.T, username, group, op string, token *HttpToken) {
-	addToGroup := `mutation updateUser($name: String, $group: String!) {
-		updateUser(input: {filter: {name: {eq: $name}}, set: {groups: [{name: $group}]}}) {
-			user {
-			  name
-			  groups {
-				name
-			}
-			}
-		  }
-		}`
-	removeFromGroup := `mutation updateUser($name: String, $group: String!) {
-		updateUser(input: {filter: {name: {eq: $name}}, remove: {groups: [{name: $group}]}}) {
-			user {
-			  name
-			  groups {
-				name
-			}
-			}
-		  }
-		}`
-
-	var query string
-	switch op {
-	case "add":
-		query = addToGroup
-	case "del":
-		query = removeFromGroup
-	default:
-		require.Fail(t, "invalid operation for updating user")
-	}
-
-	params := &GraphQLParams{
-		Query: query,
-		Variables: map[string]interface{}{
-			"name":  username,
-			"group": group,
-		},
-	}
-	resp := MakeGQLRequest(t, adminUrl, params, token)
-	require.Truef(t, len(resp.Errors) == 0, "unable to update user: %+v", resp.Errors)
+	require.NoError(t, ac.SetPermission(ctx, groupname, modifypred, admin.PermissionModify))
 }
 
 func query(t *testing.T, dg *dgo.Dgraph, shouldFail bool) {
@@ -216,14 +117,12 @@ func TestACLs(t *testing.T) {
 
 	initializeDBACLs(t, dg)
 
-	token, err := HttpLogin(&LoginParams{
-		Endpoint:  adminUrl,
-		UserID:    "groot",
-		Passwd:    "password",
-		Namespace: 0, // Galaxy namespace
-	})
+	// dg logs in as the user below, so the admin client uses its own groot login.
+	groot, cancelGroot := getDgraphClient()
+	defer cancelGroot()
+	ac, err := admin.NewClient(adminUrl, graphql.WithAuth(groot))
 	require.NoError(t, err)
-	resetUser(t, token)
+	resetUser(t, ac)
 	time.Sleep(5 * time.Second)
 
 	// All operations without ACLs should fail.
@@ -234,22 +133,22 @@ func TestACLs(t *testing.T) {
 	changeSchema(t, dg, true)
 
 	// Create unused group, everything should still fail.
-	createGroupACLs(t, unusedgroup, token)
+	createGroupACLs(t, unusedgroup, ac)
 	time.Sleep(6 * time.Second)
 	query(t, dg, true)
 	mutation(t, dg, true)
 	changeSchema(t, dg, true)
 
 	// Create dev group and link user to it. Everything should pass now.
-	createGroupACLs(t, devgroup, token)
-	addUserToGroup(t, username, devgroup, "add", token)
+	createGroupACLs(t, devgroup, ac)
+	require.NoError(t, ac.AddUserToGroups(context.Background(), username, devgroup))
 	time.Sleep(6 * time.Second)
 	query(t, dg, false)
 	mutation(t, dg, false)
 	changeSchema(t, dg, false)
 
 	// Remove user from dev group, everything should fail now.
-	addUserToGroup(t, username, devgroup, "del", token)
+	require.NoError(t, ac.RemoveUserFromGroups(context.Background(), username, devgroup))
 	time.Sleep(6 * time.Second)
 	query(t, dg, true)
 	mutation(t, dg, true)
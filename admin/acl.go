@@ -0,0 +1,253 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package admin
+
+import (
+	"context"
+	"fmt"
+)
+
+// Permission is a set of permissions on a predicate, combined using bitwise or.
+type Permission int
+
+const (
+	// PermissionModify allows altering the schema of a predicate.
+	PermissionModify Permission = 1
+	// PermissionWrite allows mutating a predicate.
+	PermissionWrite Permission = 2
+	// PermissionRead allows querying a predicate.
+	PermissionRead Permission = 4
+)
+
+// User is an ACL user.
+type User struct {
+	Name   string   `json:"name"`
+	Groups []*Group `json:"groups,omitempty"`
+}
+
+// Group is an ACL group.
+type Group struct {
+	Name  string  `json:"name"`
+	Users []*User `json:"users,omitempty"`
+	Rules []*Rule `json:"rules,omitempty"`
+}
+
+// Rule grants the members of a group permissions on a predicate.
+type Rule struct {
+	Predicate  string     `json:"predicate"`
+	Permission Permission `json:"permission"`
+}
+
+const (
+	userFields  = `name groups { name }`
+	groupFields = `name users { name } rules { predicate permission }`
+)
+
+// AddUser creates a user with the given password.
+func (c *Client) AddUser(ctx context.Context, name, password string) error {
+	const q = `mutation($name: String!, $password: String!) {
+		addUser(input: [{name: $name, password: $password}]) { user { name } }
+	}`
+	if err := c.execute(ctx, q, map[string]any{"name": name, "password": password}, nil); err != nil {
+		return fmt.Errorf("error adding user %s: %w", name, err)
+	}
+	return nil
+}
+
+// SetPassword changes the password of a user.
+func (c *Client) SetPassword(ctx context.Context, name, password string) error {
+	const q = `mutation($name: String!, $password: String!) {
+		updateUser(input: {filter: {name: {eq: $name}}, set: {password: $password}}) { user { name } }
+	}`
+	return c.updateUser(ctx, q, map[string]any{"name": name, "password": password})
+}
+
+// AddUserToGroups adds a user to the given groups.
+func (c *Client) AddUserToGroups(ctx context.Context, name string, groups ...string) error {
+	const q = `mutation($name: String!, $groups: [GroupRef]) {
+		updateUser(input: {filter: {name: {eq: $name}}, set: {groups: $groups}}) { user { name } }
+	}`
+	return c.updateUser(ctx, q, map[string]any{"name": name, "groups": groupRefs(groups)})
+}
+
+// RemoveUserFromGroups removes a user from the given groups.
+func (c *Client) RemoveUserFromGroups(ctx context.Context, name string, groups ...string) error {
+	const q = `mutation($name: String!, $groups: [GroupRef]) {
+		updateUser(input: {filter: {name: {eq: $name}}, remove: {groups: $groups}}) { user { name } }
+	}`
+	return c.updateUser(ctx, q, map[string]any{"name": name, "groups": groupRefs(groups)})
+}
+
+func (c *Client) updateUser(ctx context.Context, q string, vars map[string]any) error {
+	var out struct {
+		UpdateUser struct {
+			User []*User `json:"user"`
+		} `json:"updateUser"`
+	}
+	name := vars["name"]
+	if err := c.execute(ctx, q, vars, &out); err != nil {
+		return fmt.Errorf("error updating user %s: %w", name, err)
+	}
+	if len(out.UpdateUser.User) == 0 {
+		return fmt.Errorf("user %s: %w", name, ErrNotFound)
+	}
+	return nil
+}
+
+// DeleteUser deletes a user.
+func (c *Client) DeleteUser(ctx context.Context, name string) error {
+	const q = `mutation($name: String!) {
+		deleteUser(filter: {name: {eq: $name}}) { numUids }
+	}`
+	var out struct {
+		DeleteUser struct {
+			NumUids int `json:"numUids"`
+		} `json:"deleteUser"`
+	}
+	if err := c.execute(ctx, q, map[string]any{"name": name}, &out); err != nil {
+		return fmt.Errorf("error deleting user %s: %w", name, err)
+	}
+	if out.DeleteUser.NumUids == 0 {
+		return fmt.Errorf("user %s: %w", name, ErrNotFound)
+	}
+	return nil
+}
+
+// GetUser returns a user along with the groups it is a member of.
+func (c *Client) GetUser(ctx context.Context, name string) (*User, error) {
+	const q = `query($name: String!) { getUser(name: $name) { ` + userFields + ` } }`
+	var out struct {
+		GetUser *User `json:"getUser"`
+	}
+	if err := c.execute(ctx, q, map[string]any{"name": name}, &out); err != nil {
+		return nil, fmt.Errorf("error getting user %s: %w", name, err)
+	}
+	if out.GetUser == nil {
+		return nil, fmt.Errorf("user %s: %w", name, ErrNotFound)
+	}
+	return out.GetUser, nil
+}
+
+// ListUsers returns all the users along with the groups they are members of.
+func (c *Client) ListUsers(ctx context.Context) ([]*User, error) {
+	const q = `query { queryUser { ` + userFields + ` } }`
+	var out struct {
+		QueryUser []*User `json:"queryUser"`
+	}
+	if err := c.execute(ctx, q, nil, &out); err != nil {
+		return nil, fmt.Errorf("error listing users: %w", err)
+	}
+	return out.QueryUser, nil
+}
+
+// AddGroup creates a group with the given rules.
+func (c *Client) AddGroup(ctx context.Context, name string, rules ...*Rule) error {
+	const q = `mutation($name: String!, $rules: [RuleRef]) {
+		addGroup(input: [{name: $name, rules: $rules}]) { group { name } }
+	}`
+	if err := c.execute(ctx, q, map[string]any{"name": name, "rules": nonNil(rules)}, nil); err != nil {
+		return fmt.Errorf("error adding group %s: %w", name, err)
+	}
+	return nil
+}
+
+// SetPermission sets the permission of the members of a group on a predicate,
+// replacing any existing permission of the group on the predicate.
+func (c *Client) SetPermission(ctx context.Context, group, predicate string, perm Permission) error {
+	const q = `mutation($name: String!, $rules: [RuleRef]) {
+		updateGroup(input: {filter: {name: {eq: $name}}, set: {rules: $rules}}) { group { name } }
+	}`
+	rules := []*Rule{{Predicate: predicate, Permission: perm}}
+	return c.updateGroup(ctx, q, map[string]any{"name": group, "rules": rules})
+}
+
+// RemovePermissions removes the permissions of the members of a group on the
+// given predicates.
+func (c *Client) RemovePermissions(ctx context.Context, group string, predicates ...string) error {
+	const q = `mutation($name: String!, $predicates: [String!]) {
+		updateGroup(input: {filter: {name: {eq: $name}}, remove: {rules: $predicates}}) { group { name } }
+	}`
+	return c.updateGroup(ctx, q, map[string]any{"name": group, "predicates": nonNil(predicates)})
+}
+
+func (c *Client) updateGroup(ctx context.Context, q string, vars map[string]any) error {
+	var out struct {
+		UpdateGroup struct {
+			Group []*Group `json:"group"`
+		} `json:"updateGroup"`
+	}
+	name := vars["name"]
+	if err := c.execute(ctx, q, vars, &out); err != nil {
+		return fmt.Errorf("error updating group %s: %w", name, err)
+	}
+	if len(out.UpdateGroup.Group) == 0 {
+		return fmt.Errorf("group %s: %w", name, ErrNotFound)
+	}
+	return nil
+}
+
+// DeleteGroup deletes a group.
+func (c *Client) DeleteGroup(ctx context.Context, name string) error {
+	const q = `mutation($name: String!) {
+		deleteGroup(filter: {name: {eq: $name}}) { numUids }
+	}`
+	var out struct {
+		DeleteGroup struct {
+			NumUids int `json:"numUids"`
+		} `json:"deleteGroup"`
+	}
+	if err := c.execute(ctx, q, map[string]any{"name": name}, &out); err != nil {
+		return fmt.Errorf("error deleting group %s: %w", name, err)
+	}
+	if out.DeleteGroup.NumUids == 0 {
+		return fmt.Errorf("group %s: %w", name, ErrNotFound)
+	}
+	return nil
+}
+
+// GetGroup returns a group along with its members and rules.
+func (c *Client) GetGroup(ctx context.Context, name string) (*Group, error) {
+	const q = `query($name: String!) { getGroup(name: $name) { ` + groupFields + ` } }`
+	var out struct {
+		GetGroup *Group `json:"getGroup"`
+	}
+	if err := c.execute(ctx, q, map[string]any{"name": name}, &out); err != nil {
+		return nil, fmt.Errorf("error getting group %s: %w", name, err)
+	}
+	if out.GetGroup == nil {
+		return nil, fmt.Errorf("group %s: %w", name, ErrNotFound)
+	}
+	return out.GetGroup, nil
+}
+
+// ListGroups returns all the groups along with their members and rules.
+func (c *Client) ListGroups(ctx context.Context) ([]*Group, error) {
+	const q = `query { queryGroup { ` + groupFields + ` } }`
+	var out struct {
+		QueryGroup []*Group `json:"queryGroup"`
+	}
+	if err := c.execute(ctx, q, nil, &out); err != nil {
+		return nil, fmt.Errorf("error listing groups: %w", err)
+	}
+	return out.QueryGroup, nil
+}
+
+func groupRefs(groups []string) []map[string]string {
+	refs := make([]map[string]string, 0, len(groups))
+	for _, g := range groups {
+		refs = append(refs, map[string]string{"name": g})
+	}
+	return refs
+}
+
+// nonNil returns an empty slice for nil, so that it is sent as an empty list
+// rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
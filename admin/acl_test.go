@@ -0,0 +1,143 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgo/v250/admin"
+	"github.com/dgraph-io/dgo/v250/graphql"
+)
+
+// newFakeAdmin starts a server answering each request with the response of the
+// first operation found in its query, and records the variables of the requests.
+func newFakeAdmin(t *testing.T, responses map[string]string) (*admin.Client, *[]map[string]any) {
+	var vars []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "token", r.Header.Get("X-Dgraph-AccessToken"))
+		var req graphql.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		vars = append(vars, req.Variables)
+		for op, resp := range responses {
+			if regexp.MustCompile(`\b` + op + `\b`).MatchString(req.Query) {
+				_, _ = w.Write([]byte(resp))
+				return
+			}
+		}
+		http.Error(w, "unexpected query: "+req.Query, http.StatusBadRequest)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := admin.NewClient(srv.URL, graphql.WithHeader("X-Dgraph-AccessToken", "token"))
+	require.NoError(t, err)
+	return c, &vars
+}
+
+func TestACLOffline(t *testing.T) {
+	c, vars := newFakeAdmin(t, map[string]string{
+		"addUser":     `{"data": {"addUser": {"user": [{"name": "alice"}]}}}`,
+		"updateUser":  `{"data": {"updateUser": {"user": [{"name": "alice"}]}}}`,
+		"deleteUser":  `{"data": {"deleteUser": {"numUids": 1}}}`,
+		"addGroup":    `{"data": {"addGroup": {"group": [{"name": "dev"}]}}}`,
+		"updateGroup": `{"data": {"updateGroup": {"group": [{"name": "dev"}]}}}`,
+		"deleteGroup": `{"data": {"deleteGroup": {"numUids": 1}}}`,
+		"getUser":     `{"data": {"getUser": {"name": "alice", "groups": [{"name": "dev"}]}}}`,
+		"queryUser":   `{"data": {"queryUser": [{"name": "alice"}, {"name": "groot", "groups": [{"name": "guardians"}]}]}}`,
+		"getGroup": `{"data": {"getGroup": {"name": "dev", "users": [{"name": "alice"}],
+			"rules": [{"predicate": "name", "permission": 6}]}}}`,
+		"queryGroup": `{"data": {"queryGroup": [{"name": "dev"}]}}`,
+	})
+	ctx := context.Background()
+
+	require.NoError(t, c.AddUser(ctx, "alice", "secret"))
+	require.NoError(t, c.SetPassword(ctx, "alice", "secret2"))
+	require.NoError(t, c.AddGroup(ctx, "dev"))
+	require.NoError(t, c.SetPermission(ctx, "dev", "name", admin.PermissionRead|admin.PermissionWrite))
+	require.NoError(t, c.RemovePermissions(ctx, "dev", "age"))
+	require.NoError(t, c.AddUserToGroups(ctx, "alice", "dev", "ops"))
+	require.NoError(t, c.RemoveUserFromGroups(ctx, "alice", "ops"))
+	require.NoError(t, c.DeleteUser(ctx, "alice"))
+	require.NoError(t, c.DeleteGroup(ctx, "dev"))
+
+	data, err := json.Marshal(*vars)
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"name": "alice", "password": "secret"},
+		{"name": "alice", "password": "secret2"},
+		{"name": "dev", "rules": []},
+		{"name": "dev", "rules": [{"predicate": "name", "permission": 6}]},
+		{"name": "dev", "predicates": ["age"]},
+		{"name": "alice", "groups": [{"name": "dev"}, {"name": "ops"}]},
+		{"name": "alice", "groups": [{"name": "ops"}]},
+		{"name": "alice"},
+		{"name": "dev"}
+	]`, string(data))
+
+	u, err := c.GetUser(ctx, "alice")
+	require.NoError(t, err)
+	require.Equal(t, &admin.User{Name: "alice", Groups: []*admin.Group{{Name: "dev"}}}, u)
+	users, err := c.ListUsers(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	require.Equal(t, "guardians", users[1].Groups[0].Name)
+
+	g, err := c.GetGroup(ctx, "dev")
+	require.NoError(t, err)
+	require.Equal(t, &admin.Group{
+		Name:  "dev",
+		Users: []*admin.User{{Name: "alice"}},
+		Rules: []*admin.Rule{{Predicate: "name", Permission: admin.PermissionRead | admin.PermissionWrite}},
+	}, g)
+	groups, err := c.ListGroups(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []*admin.Group{{Name: "dev"}}, groups)
+}
+
+func TestACLErrors(t *testing.T) {
+	c, _ := newFakeAdmin(t, map[string]string{
+		"addUser":     `{"errors": [{"message": "user alice already exists"}]}`,
+		"updateUser":  `{"data": {"updateUser": {"user": []}}}`,
+		"updateGroup": `{"data": {"updateGroup": {"group": []}}}`,
+		"getUser":     `{"data": {"getUser": null}}`,
+		"getGroup":    `{"data": {"getGroup": null}}`,
+		"deleteUser":  `{"data": {"deleteUser": {"numUids": 0}}}`,
+		"deleteGroup": `{"data": {"deleteGroup": {"numUids": 0}}}`,
+	})
+	ctx := context.Background()
+
+	err := c.AddUser(ctx, "alice", "secret")
+	require.EqualError(t, err, "error adding user alice: user alice already exists")
+	var errs graphql.Errors
+	require.True(t, errors.As(err, &errs))
+
+	err = c.AddUserToGroups(ctx, "bob", "dev")
+	require.ErrorIs(t, err, admin.ErrNotFound)
+	require.EqualError(t, err, "user bob: not found")
+	err = c.SetPermission(ctx, "ops", "name", admin.PermissionRead)
+	require.ErrorIs(t, err, admin.ErrNotFound)
+	require.EqualError(t, err, "group ops: not found")
+	_, err = c.GetUser(ctx, "bob")
+	require.ErrorIs(t, err, admin.ErrNotFound)
+	_, err = c.GetGroup(ctx, "ops")
+	require.ErrorIs(t, err, admin.ErrNotFound)
+	err = c.DeleteUser(ctx, "bob")
+	require.ErrorIs(t, err, admin.ErrNotFound)
+	require.EqualError(t, err, "user bob: not found")
+	err = c.DeleteGroup(ctx, "ops")
+	require.ErrorIs(t, err, admin.ErrNotFound)
+	require.EqualError(t, err, "group ops: not found")
+
+	_, err = admin.NewClient("")
+	require.ErrorContains(t, err, "GraphQL endpoint URL cannot be empty")
+}
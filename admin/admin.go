@@ -0,0 +1,38 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package admin is a client for the /admin GraphQL endpoint of a Dgraph cluster.
+// Passing graphql.WithAuth with a dgo.Dgraph client logged in as a guardian to
+// NewClient authenticates the requests using the JWT of the client.
+package admin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dgraph-io/dgo/v250/graphql"
+)
+
+// ErrNotFound is returned when the user or group of an operation doesn't exist.
+var ErrNotFound = errors.New("not found")
+
+// Client runs operations against the /admin endpoint.
+type Client struct {
+	gql *graphql.Client
+}
+
+// NewClient creates a client for the admin endpoint at the given URL, e.g.
+// http://localhost:8080/admin.
+func NewClient(url string, opts ...graphql.ClientOption) (*Client, error) {
+	gql, err := graphql.NewClient(url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{gql: gql}, nil
+}
+
+func (c *Client) execute(ctx context.Context, query string, vars map[string]any, out any) error {
+	return c.gql.Execute(ctx, query, vars, out)
+}
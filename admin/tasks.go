@@ -0,0 +1,246 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTaskFailed is returned by WaitForTask when the task fails.
+var ErrTaskFailed = errors.New("task failed")
+
+// StorageCredentials are the credentials used to access the backup or export
+// location when it is in object storage such as S3 or MinIO.
+type StorageCredentials struct {
+	AccessKey    string `json:"accessKey,omitempty"`
+	SecretKey    string `json:"secretKey,omitempty"`
+	SessionToken string `json:"sessionToken,omitempty"`
+	// Anonymous accesses the location without credentials.
+	Anonymous bool `json:"anonymous,omitempty"`
+}
+
+// BackupRequest describes a backup, see Client.Backup.
+type BackupRequest struct {
+	// Destination is the URL or path of the backup location, e.g. s3://... or
+	// /path/on/alpha.
+	Destination string `json:"destination"`
+	// ForceFull takes a full backup instead of an incremental one.
+	ForceFull bool `json:"forceFull,omitempty"`
+	StorageCredentials
+}
+
+// RestoreRequest describes a restore, see Client.Restore.
+type RestoreRequest struct {
+	// Location is the URL or path of the backup location.
+	Location string `json:"location"`
+	// BackupID is the ID of the backup series to restore, the latest series if empty.
+	BackupID string `json:"backupId,omitempty"`
+	// BackupNum is the number of the backup in the series to restore up to, all
+	// the backups in the series if zero.
+	BackupNum int `json:"backupNum,omitempty"`
+	// EncryptionKeyFile is the path on the alphas of the key the backup was
+	// encrypted with.
+	EncryptionKeyFile string `json:"encryptionKeyFile,omitempty"`
+	StorageCredentials
+}
+
+// ExportRequest describes an export, see Client.Export.
+type ExportRequest struct {
+	// Format is the format of the exported data, rdf or json; rdf if empty.
+	Format string `json:"format,omitempty"`
+	// Destination is the URL or path of the export location, the export
+	// directory of the alphas if empty.
+	Destination string `json:"destination,omitempty"`
+	// Namespace is the namespace to export, the namespace of the logged in user
+	// if zero.
+	Namespace uint64 `json:"-"`
+	// AllNamespaces exports all the namespaces. Only the guardians of the galaxy
+	// namespace can export all the namespaces.
+	AllNamespaces bool `json:"-"`
+	StorageCredentials
+}
+
+// TaskStatus is the status of a backup or export task.
+type TaskStatus string
+
+// The statuses of a task.
+const (
+	TaskQueued  TaskStatus = "Queued"
+	TaskRunning TaskStatus = "Running"
+	TaskFailed  TaskStatus = "Failed"
+	TaskSuccess TaskStatus = "Success"
+	TaskUnknown TaskStatus = "Unknown"
+)
+
+// Task is the state of a backup or export task.
+type Task struct {
+	ID          string     `json:"-"`
+	Kind        string     `json:"kind"`
+	Status      TaskStatus `json:"status"`
+	LastUpdated time.Time  `json:"lastUpdated"`
+}
+
+// Done returns true if the task has completed, successfully or not.
+func (t *Task) Done() bool {
+	return t.Status == TaskSuccess || t.Status == TaskFailed
+}
+
+// Backup starts a backup of the cluster and returns the ID of the task running it.
+func (c *Client) Backup(ctx context.Context, req *BackupRequest) (string, error) {
+	const q = `mutation($input: BackupInput!) {
+		backup(input: $input) { response { code message } taskId }
+	}`
+	var out struct {
+		Backup struct {
+			TaskID string `json:"taskId"`
+		} `json:"backup"`
+	}
+	if err := c.execute(ctx, q, map[string]any{"input": req}, &out); err != nil {
+		return "", fmt.Errorf("error starting backup: %w", err)
+	}
+	if out.Backup.TaskID == "" {
+		return "", errors.New("no task ID found in the backup response")
+	}
+	return out.Backup.TaskID, nil
+}
+
+// Export starts an export of the cluster and returns the ID of the task running it.
+func (c *Client) Export(ctx context.Context, req *ExportRequest) (string, error) {
+	const q = `mutation($input: ExportInput!) {
+		export(input: $input) { response { code message } taskId }
+	}`
+	input := struct {
+		*ExportRequest
+		Namespace *int64 `json:"namespace,omitempty"`
+	}{ExportRequest: req}
+	switch {
+	case req.AllNamespaces:
+		ns := int64(-1)
+		input.Namespace = &ns
+	case req.Namespace != 0:
+		ns := int64(req.Namespace)
+		input.Namespace = &ns
+	}
+
+	var out struct {
+		Export struct {
+			TaskID string `json:"taskId"`
+		} `json:"export"`
+	}
+	if err := c.execute(ctx, q, map[string]any{"input": input}, &out); err != nil {
+		return "", fmt.Errorf("error starting export: %w", err)
+	}
+	if out.Export.TaskID == "" {
+		return "", errors.New("no task ID found in the export response")
+	}
+	return out.Export.TaskID, nil
+}
+
+// Restore starts restoring a backup into the cluster. Restores don't run as
+// tasks; the restore has started once Restore returns.
+func (c *Client) Restore(ctx context.Context, req *RestoreRequest) error {
+	const q = `mutation($input: RestoreInput!) {
+		restore(input: $input) { code message }
+	}`
+	var out struct {
+		Restore struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"restore"`
+	}
+	if err := c.execute(ctx, q, map[string]any{"input": req}, &out); err != nil {
+		return fmt.Errorf("error starting restore: %w", err)
+	}
+	if out.Restore.Code != "Success" {
+		return fmt.Errorf("error starting restore: %s", out.Restore.Message)
+	}
+	return nil
+}
+
+// Task returns the state of the task with the given ID.
+func (c *Client) Task(ctx context.Context, id string) (*Task, error) {
+	const q = `query($id: String!) {
+		task(input: {id: $id}) { kind status lastUpdated }
+	}`
+	var out struct {
+		Task *Task `json:"task"`
+	}
+	if err := c.execute(ctx, q, map[string]any{"id": id}, &out); err != nil {
+		return nil, fmt.Errorf("error getting task %s: %w", id, err)
+	}
+	if out.Task == nil {
+		return nil, fmt.Errorf("task %s: %w", id, ErrNotFound)
+	}
+	out.Task.ID = id
+	return out.Task, nil
+}
+
+type waitOptions struct {
+	interval time.Duration
+	progress func(*Task)
+}
+
+// WaitOption is a function that modifies the options of WaitForTask.
+type WaitOption func(*waitOptions) error
+
+// WithPollInterval sets how often the state of the task is polled, every
+// second by default.
+func WithPollInterval(d time.Duration) WaitOption {
+	return func(o *waitOptions) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid poll interval: %v", d)
+		}
+		o.interval = d
+		return nil
+	}
+}
+
+// WithProgress calls fn with the state of the task every time it is polled.
+func WithProgress(fn func(*Task)) WaitOption {
+	return func(o *waitOptions) error {
+		o.progress = fn
+		return nil
+	}
+}
+
+// WaitForTask polls the task with the given ID until it completes or ctx is
+// done, and returns its final state. The error wraps ErrTaskFailed if the task
+// failed.
+func (c *Client) WaitForTask(ctx context.Context, id string, opts ...WaitOption) (*Task, error) {
+	o := waitOptions{interval: time.Second}
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+	for {
+		task, err := c.Task(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if o.progress != nil {
+			o.progress(task)
+		}
+		if task.Status == TaskFailed {
+			return task, fmt.Errorf("task %s: %w", id, ErrTaskFailed)
+		}
+		if task.Done() {
+			return task, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return task, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
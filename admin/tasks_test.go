@@ -0,0 +1,134 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgo/v250/admin"
+)
+
+func TestTasksOffline(t *testing.T) {
+	c, vars := newFakeAdmin(t, map[string]string{
+		"backup":  `{"data": {"backup": {"response": {"code": "Success"}, "taskId": "0x1"}}}`,
+		"export":  `{"data": {"export": {"response": {"code": "Success"}, "taskId": "0x2"}}}`,
+		"restore": `{"data": {"restore": {"code": "Success", "message": "Restore operation started."}}}`,
+	})
+	ctx := context.Background()
+
+	id, err := c.Backup(ctx, &admin.BackupRequest{
+		Destination:        "s3://s3.us-west-2.amazonaws.com/backups",
+		ForceFull:          true,
+		StorageCredentials: admin.StorageCredentials{AccessKey: "ak", SecretKey: "sk"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "0x1", id)
+
+	id, err = c.Export(ctx, &admin.ExportRequest{Format: "json", AllNamespaces: true})
+	require.NoError(t, err)
+	require.Equal(t, "0x2", id)
+	_, err = c.Export(ctx, &admin.ExportRequest{Namespace: 2})
+	require.NoError(t, err)
+	_, err = c.Export(ctx, &admin.ExportRequest{})
+	require.NoError(t, err)
+
+	require.NoError(t, c.Restore(ctx, &admin.RestoreRequest{
+		Location:           "/backups",
+		BackupID:           "loving_jones5",
+		StorageCredentials: admin.StorageCredentials{Anonymous: true},
+	}))
+
+	data, err := json.Marshal(*vars)
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"input": {"destination": "s3://s3.us-west-2.amazonaws.com/backups", "forceFull": true,
+			"accessKey": "ak", "secretKey": "sk"}},
+		{"input": {"format": "json", "namespace": -1}},
+		{"input": {"namespace": 2}},
+		{"input": {}},
+		{"input": {"location": "/backups", "backupId": "loving_jones5", "anonymous": true}}
+	]`, string(data))
+}
+
+func TestTasksErrors(t *testing.T) {
+	c, _ := newFakeAdmin(t, map[string]string{
+		"backup":  `{"errors": [{"message": "backup is not enabled"}]}`,
+		"export":  `{"data": {"export": {"response": {"code": "Success"}}}}`,
+		"restore": `{"data": {"restore": {"code": "Failure", "message": "no backups found"}}}`,
+		"task":    `{"data": {"task": null}}`,
+	})
+	ctx := context.Background()
+
+	_, err := c.Backup(ctx, &admin.BackupRequest{Destination: "/backups"})
+	require.EqualError(t, err, "error starting backup: backup is not enabled")
+	_, err = c.Export(ctx, &admin.ExportRequest{})
+	require.EqualError(t, err, "no task ID found in the export response")
+	err = c.Restore(ctx, &admin.RestoreRequest{Location: "/backups"})
+	require.EqualError(t, err, "error starting restore: no backups found")
+	_, err = c.Task(ctx, "0x1")
+	require.ErrorIs(t, err, admin.ErrNotFound)
+	_, err = c.WaitForTask(ctx, "0x1", admin.WithPollInterval(0))
+	require.EqualError(t, err, "invalid poll interval: 0s")
+}
+
+// newTaskServer serves the task query, reporting the given statuses in turn
+// and then the last one for good.
+func newTaskServer(t *testing.T, statuses ...admin.TaskStatus) (*admin.Client, *atomic.Int32) {
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(polls.Add(1)) - 1
+		if i >= len(statuses) {
+			i = len(statuses) - 1
+		}
+		_, _ = fmt.Fprintf(w, `{"data": {"task": {"kind": "Backup", "status": %q,
+			"lastUpdated": "2025-01-02T03:04:05Z"}}}`, statuses[i])
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := admin.NewClient(srv.URL)
+	require.NoError(t, err)
+	return c, &polls
+}
+
+func TestWaitForTaskOffline(t *testing.T) {
+	ctx := context.Background()
+	c, polls := newTaskServer(t, admin.TaskQueued, admin.TaskRunning, admin.TaskSuccess)
+
+	var seen []admin.TaskStatus
+	task, err := c.WaitForTask(ctx, "0x1", admin.WithPollInterval(time.Millisecond),
+		admin.WithProgress(func(task *admin.Task) { seen = append(seen, task.Status) }))
+	require.NoError(t, err)
+	require.Equal(t, &admin.Task{
+		ID:          "0x1",
+		Kind:        "Backup",
+		Status:      admin.TaskSuccess,
+		LastUpdated: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, task)
+	require.Equal(t, []admin.TaskStatus{admin.TaskQueued, admin.TaskRunning, admin.TaskSuccess}, seen)
+	require.EqualValues(t, 3, polls.Load())
+
+	c, _ = newTaskServer(t, admin.TaskRunning, admin.TaskFailed)
+	task, err = c.WaitForTask(ctx, "0x2", admin.WithPollInterval(time.Millisecond))
+	require.ErrorIs(t, err, admin.ErrTaskFailed)
+	require.EqualError(t, err, "task 0x2: task failed")
+	require.Equal(t, admin.TaskFailed, task.Status)
+
+	// The wait stops once the context is done.
+	c, _ = newTaskServer(t, admin.TaskRunning)
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = c.WaitForTask(ctx, "0x3", admin.WithPollInterval(time.Millisecond))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -37,8 +37,12 @@ func (d *Dgraph) SetSchema(ctx context.Context, schema string) error {
 }
 
 func (d *Dgraph) doAlter(ctx context.Context, req *api.Operation) error {
-	_, err := doWithRetryLogin(ctx, d, func(dc api.DgraphClient) (*api.Payload, error) {
-		return dc.Alter(d.getContext(ctx), req)
-	})
+	_, err := doWithRetryLogin(ctx, d, "Alter", req,
+		func(ctx context.Context, dc api.DgraphClient) (*api.Payload, error) {
+			return dc.Alter(ctx, req)
+		})
+	if err == nil && d.cache != nil {
+		d.cache.clear()
+	}
 	return err
 }
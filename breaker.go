@@ -0,0 +1,259 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+// ErrCircuitOpen is matched by the errors returned for requests to an endpoint whose
+// circuit breaker is open, see WithCircuitBreaker.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError is returned for requests to an endpoint whose circuit breaker is
+// open. It matches ErrCircuitOpen, and has the gRPC status code Unavailable.
+type CircuitOpenError struct {
+	// Endpoint is the address of the endpoint.
+	Endpoint string
+	// Until is when the circuit breaker will let a request probe the endpoint again.
+	Until time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker for endpoint %s is open", e.Endpoint)
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// GRPCStatus returns the gRPC status of the error.
+func (e *CircuitOpenError) GRPCStatus() *status.Status {
+	return status.New(codes.Unavailable, e.Error())
+}
+
+// WithCircuitBreaker adds a circuit breaker to each endpoint of the client. Once
+// failures consecutive requests to an endpoint have failed with Unavailable or
+// DeadlineExceeded, the breaker opens and the requests to the endpoint fail right
+// away with a CircuitOpenError. After the cooldown, the next request first probes
+// the endpoint using CheckVersion, which must succeed within the cooldown for the
+// breaker to close again. New transactions and requests avoid the endpoints whose
+// breaker is open, as long as there are others.
+func WithCircuitBreaker(failures int, cooldown time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if failures <= 0 {
+			return fmt.Errorf("invalid circuit breaker failures: %d", failures)
+		}
+		if cooldown <= 0 {
+			return fmt.Errorf("invalid circuit breaker cooldown: %v", cooldown)
+		}
+		o.breakerFailures = failures
+		o.breakerCooldown = cooldown
+		return nil
+	}
+}
+
+// breakerClient is a circuit breaker for the requests to one endpoint.
+type breakerClient struct {
+	api.DgraphClient
+
+	endpoint string
+	failures int
+	cooldown time.Duration
+
+	mu        sync.Mutex
+	failed    int       // number of consecutive failures
+	openUntil time.Time // zero if the breaker is closed
+	probing   bool
+}
+
+// withBreaker returns dc wrapped in a circuit breaker if co enables them.
+func withBreaker(dc api.DgraphClient, endpoint string, co *clientOptions) api.DgraphClient {
+	if co.breakerFailures == 0 {
+		return dc
+	}
+	return &breakerClient{DgraphClient: dc, endpoint: endpoint, failures: co.breakerFailures,
+		cooldown: co.breakerCooldown}
+}
+
+// isOpen returns whether requests to the endpoint would fail right away.
+func (c *breakerClient) isOpen() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.openUntil.IsZero() && (c.probing || time.Now().Before(c.openUntil))
+}
+
+// allow returns an error if the breaker is open. Once the cooldown is over, it
+// probes the endpoint and closes the breaker if the probe succeeds. The probe is
+// not canceled with ctx, e.g. when a hedged query loses, so that it still closes
+// the breaker of a healthy endpoint.
+func (c *breakerClient) allow(ctx context.Context) error {
+	c.mu.Lock()
+	if c.openUntil.IsZero() {
+		c.mu.Unlock()
+		return nil
+	}
+	if c.probing || time.Now().Before(c.openUntil) {
+		until := c.openUntil
+		c.mu.Unlock()
+		return &CircuitOpenError{Endpoint: c.endpoint, Until: until}
+	}
+	c.probing = true
+	c.mu.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		pctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.cooldown)
+		defer cancel()
+		_, err := c.DgraphClient.CheckVersion(pctx, &api.Check{})
+		done <- c.probed(err)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// probed records the outcome of a probe, returning an error if the breaker
+// stays open.
+func (c *breakerClient) probed(err error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.probing = false
+	if err != nil {
+		c.openUntil = time.Now().Add(c.cooldown)
+		return &CircuitOpenError{Endpoint: c.endpoint, Until: c.openUntil}
+	}
+	c.failed, c.openUntil = 0, time.Time{}
+	return nil
+}
+
+// record records the outcome of a request. Errors without a gRPC status, such as
+// the context errors of rate limits, come from the client and are ignored.
+func (c *breakerClient) record(err error) {
+	s, ok := status.FromError(err)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch s.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		c.failed++
+		if c.failed >= c.failures && c.openUntil.IsZero() {
+			c.openUntil = time.Now().Add(c.cooldown)
+		}
+	case codes.Canceled:
+		// The request was given up by the caller, e.g. a hedged query.
+	default:
+		c.failed = 0
+	}
+}
+
+// guard calls f unless the circuit breaker of c is open.
+func guard[T any](ctx context.Context, c *breakerClient, f func() (T, error)) (T, error) {
+	if err := c.allow(ctx); err != nil {
+		var zero T
+		return zero, err
+	}
+	resp, err := f()
+	c.record(err)
+	return resp, err
+}
+
+func (c *breakerClient) Login(ctx context.Context, in *api.LoginRequest,
+	opts ...grpc.CallOption) (*api.Response, error) {
+
+	return guard(ctx, c, func() (*api.Response, error) {
+		return c.DgraphClient.Login(ctx, in, opts...)
+	})
+}
+
+func (c *breakerClient) Query(ctx context.Context, in *api.Request,
+	opts ...grpc.CallOption) (*api.Response, error) {
+
+	return guard(ctx, c, func() (*api.Response, error) {
+		return c.DgraphClient.Query(ctx, in, opts...)
+	})
+}
+
+func (c *breakerClient) Alter(ctx context.Context, in *api.Operation,
+	opts ...grpc.CallOption) (*api.Payload, error) {
+
+	return guard(ctx, c, func() (*api.Payload, error) {
+		return c.DgraphClient.Alter(ctx, in, opts...)
+	})
+}
+
+func (c *breakerClient) CommitOrAbort(ctx context.Context, in *api.TxnContext,
+	opts ...grpc.CallOption) (*api.TxnContext, error) {
+
+	return guard(ctx, c, func() (*api.TxnContext, error) {
+		return c.DgraphClient.CommitOrAbort(ctx, in, opts...)
+	})
+}
+
+func (c *breakerClient) CheckVersion(ctx context.Context, in *api.Check,
+	opts ...grpc.CallOption) (*api.Version, error) {
+
+	return guard(ctx, c, func() (*api.Version, error) {
+		return c.DgraphClient.CheckVersion(ctx, in, opts...)
+	})
+}
+
+func (c *breakerClient) RunDQL(ctx context.Context, in *api.RunDQLRequest,
+	opts ...grpc.CallOption) (*api.Response, error) {
+
+	return guard(ctx, c, func() (*api.Response, error) {
+		return c.DgraphClient.RunDQL(ctx, in, opts...)
+	})
+}
+
+func (c *breakerClient) AllocateIDs(ctx context.Context, in *api.AllocateIDsRequest,
+	opts ...grpc.CallOption) (*api.AllocateIDsResponse, error) {
+
+	return guard(ctx, c, func() (*api.AllocateIDsResponse, error) {
+		return c.DgraphClient.AllocateIDs(ctx, in, opts...)
+	})
+}
+
+func (c *breakerClient) CreateNamespace(ctx context.Context, in *api.CreateNamespaceRequest,
+	opts ...grpc.CallOption) (*api.CreateNamespaceResponse, error) {
+
+	return guard(ctx, c, func() (*api.CreateNamespaceResponse, error) {
+		return c.DgraphClient.CreateNamespace(ctx, in, opts...)
+	})
+}
+
+func (c *breakerClient) DropNamespace(ctx context.Context, in *api.DropNamespaceRequest,
+	opts ...grpc.CallOption) (*api.DropNamespaceResponse, error) {
+
+	return guard(ctx, c, func() (*api.DropNamespaceResponse, error) {
+		return c.DgraphClient.DropNamespace(ctx, in, opts...)
+	})
+}
+
+func (c *breakerClient) ListNamespaces(ctx context.Context, in *api.ListNamespacesRequest,
+	opts ...grpc.CallOption) (*api.ListNamespacesResponse, error) {
+
+	return guard(ctx, c, func() (*api.ListNamespacesResponse, error) {
+		return c.DgraphClient.ListNamespaces(ctx, in, opts...)
+	})
+}
@@ -0,0 +1,133 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/dgraph-io/dgo/v250"
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+func TestCircuitBreakerOffline(t *testing.T) {
+	var down atomic.Bool
+	stuck := &fakeDgraphServer{
+		query: func(context.Context, *api.Request) (*api.Response, error) {
+			if down.Load() {
+				return nil, status.Error(codes.Unavailable, "stuck")
+			}
+			return &api.Response{Json: []byte(`{}`)}, nil
+		},
+	}
+	healthy := &fakeDgraphServer{}
+	stuckAddr, _ := startFakeServer(t, "127.0.0.1:0", nil, stuck)
+	healthyAddr, _ := startFakeServer(t, "127.0.0.1:0", nil, healthy)
+
+	dg, err := dgo.NewRoundRobinClient([]string{stuckAddr, healthyAddr},
+		dgo.WithGrpcOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		dgo.WithLoadBalancingPolicy(dgo.LoadBalanceRoundRobin),
+		dgo.WithCircuitBreaker(2, 100*time.Millisecond))
+	require.NoError(t, err)
+	defer dg.Close()
+
+	ctx := context.Background()
+	query := func() error {
+		_, err := dg.NewReadOnlyTxn().Query(ctx, `{ q(func: uid(1)) { uid } }`)
+		return err
+	}
+
+	// Two consecutive failures open the breaker of the stuck endpoint.
+	down.Store(true)
+	for range 2 {
+		require.Equal(t, codes.Unavailable, status.Code(query()))
+		require.NoError(t, query())
+	}
+	require.EqualValues(t, 2, stuck.queries.Load())
+
+	_, err = dg.GetAPIClients()[0].Query(ctx, &api.Request{Query: `{}`})
+	require.ErrorIs(t, err, dgo.ErrCircuitOpen)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	var openErr *dgo.CircuitOpenError
+	require.ErrorAs(t, err, &openErr)
+	require.Equal(t, stuckAddr, openErr.Endpoint)
+
+	// New transactions avoid the stuck endpoint.
+	for range 4 {
+		require.NoError(t, query())
+	}
+	require.EqualValues(t, 2, stuck.queries.Load())
+	require.EqualValues(t, 6, healthy.queries.Load())
+
+	// After the cooldown, a successful probe closes the breaker.
+	down.Store(false)
+	time.Sleep(150 * time.Millisecond)
+	for range 4 {
+		require.NoError(t, query())
+	}
+	require.EqualValues(t, 4, stuck.queries.Load())
+	require.EqualValues(t, 8, healthy.queries.Load())
+}
+
+func TestCircuitBreakerCanceledProbeOffline(t *testing.T) {
+	var down atomic.Bool
+	srv := &fakeDgraphServer{
+		query: func(context.Context, *api.Request) (*api.Response, error) {
+			if down.Load() {
+				return nil, status.Error(codes.Unavailable, "stuck")
+			}
+			return &api.Response{Json: []byte(`{}`)}, nil
+		},
+	}
+	addr, _ := startFakeServer(t, "127.0.0.1:0", nil, srv)
+
+	dg, err := dgo.NewClient(addr,
+		dgo.WithGrpcOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		dgo.WithCircuitBreaker(1, 50*time.Millisecond))
+	require.NoError(t, err)
+	defer dg.Close()
+	dc := dg.GetAPIClients()[0]
+
+	ctx := context.Background()
+	down.Store(true)
+	_, err = dc.Query(ctx, &api.Request{Query: `{}`})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	down.Store(false)
+	time.Sleep(100 * time.Millisecond)
+
+	// The probe of a request canceled by its caller still closes the breaker.
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = dc.Query(cctx, &api.Request{Query: `{}`})
+	require.Error(t, err)
+	require.Eventually(t, func() bool {
+		_, err := dc.Query(ctx, &api.Request{Query: `{}`})
+		return err == nil
+	}, 40*time.Millisecond, time.Millisecond)
+}
+
+func TestCircuitBreakerOptions(t *testing.T) {
+	tests := []struct {
+		opt dgo.ClientOption
+		err string
+	}{
+		{opt: dgo.WithCircuitBreaker(0, time.Second), err: "invalid circuit breaker failures: 0"},
+		{opt: dgo.WithCircuitBreaker(3, 0), err: "invalid circuit breaker cooldown: 0s"},
+	}
+
+	for _, tc := range tests {
+		_, err := dgo.NewClient("127.0.0.1:9180", tc.opt)
+		require.EqualError(t, err, tc.err)
+	}
+}
@@ -0,0 +1,312 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+// WithQueryCache caches the responses of the queries of read-only transactions,
+// keyed by the query, its variables and the user and namespace of the client. At
+// most maxEntries responses are cached, the least recently used being evicted
+// first, and each for at most ttl.
+//
+// A response is served from the cache only for the first query of a transaction,
+// which then reads the snapshot of the cached response. Commits and schema
+// changes made by the client invalidate the responses of the queries using the
+// modified predicates, but changes made by other clients are only seen once the
+// responses expire. Interceptors are called for the responses served from the
+// cache too, with no request being sent.
+func WithQueryCache(maxEntries int, ttl time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if maxEntries <= 0 {
+			return fmt.Errorf("invalid query cache size: %d", maxEntries)
+		}
+		if ttl <= 0 {
+			return fmt.Errorf("invalid query cache TTL: %v", ttl)
+		}
+		o.cache = newQueryCache(maxEntries, ttl)
+		return nil
+	}
+}
+
+// queryCache is an LRU cache of query responses, indexed by the predicates the
+// queries may use so that commits can invalidate them.
+type queryCache struct {
+	maxEntries int
+	ttl        time.Duration
+
+	mu sync.Mutex
+	// gen is incremented by every invalidation, so that the responses of queries
+	// that were in flight during an invalidation are not cached.
+	gen      uint64
+	lru      *list.List // of *cacheEntry, most recently used first
+	entries  map[string]*list.Element
+	byPred   map[string]map[*cacheEntry]struct{}
+	wildcard map[*cacheEntry]struct{}
+}
+
+type cacheEntry struct {
+	key     string
+	resp    *api.Response
+	expires time.Time
+	// preds holds the predicates the query may use, unless wildcard is set
+	// because the query can use any predicate.
+	preds    []string
+	wildcard bool
+}
+
+func newQueryCache(maxEntries int, ttl time.Duration) *queryCache {
+	c := &queryCache{maxEntries: maxEntries, ttl: ttl}
+	c.reset()
+	return c
+}
+
+// reset must be called with c.mu held, unless c is not yet in use.
+func (c *queryCache) reset() {
+	c.lru = list.New()
+	c.entries = make(map[string]*list.Element)
+	c.byPred = make(map[string]map[*cacheEntry]struct{})
+	c.wildcard = make(map[*cacheEntry]struct{})
+}
+
+// cacheKey returns the cache key of a query request of the given user and namespace.
+// Users see different data depending on their ACL permissions, so their responses
+// are cached separately.
+func cacheKey(user string, ns uint64, req *api.Request) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d:%s|%d|%t|%d|%d:%s", len(user), user, ns, req.BestEffort, req.RespFormat,
+		len(req.Query), req.Query)
+	names := make([]string, 0, len(req.Vars))
+	for name := range req.Vars {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		v := req.Vars[name]
+		fmt.Fprintf(&b, "|%d:%s=%d:%s", len(name), name, len(v), v)
+	}
+	return b.String()
+}
+
+// generation returns the current generation, to be passed to put along with the
+// response of a query sent after calling it.
+func (c *queryCache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+// get returns a copy of the cached response for the key, if any.
+func (c *queryCache) get(key string) (*api.Response, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := elem.Value.(*cacheEntry)
+	if time.Now().After(e.expires) {
+		c.remove(e)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return proto.Clone(e.resp).(*api.Response), true
+}
+
+// put caches a copy of the response of the query, unless the cache has been
+// invalidated since gen was obtained.
+func (c *queryCache) put(gen uint64, key, query string, resp *api.Response) {
+	preds, wildcard := queryPredicates(query)
+	e := &cacheEntry{
+		key:      key,
+		resp:     proto.Clone(resp).(*api.Response),
+		expires:  time.Now().Add(c.ttl),
+		preds:    preds,
+		wildcard: wildcard,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if gen != c.gen {
+		return
+	}
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem.Value.(*cacheEntry))
+	}
+	c.entries[key] = c.lru.PushFront(e)
+	if e.wildcard {
+		c.wildcard[e] = struct{}{}
+	}
+	for _, pred := range e.preds {
+		if c.byPred[pred] == nil {
+			c.byPred[pred] = make(map[*cacheEntry]struct{})
+		}
+		c.byPred[pred][e] = struct{}{}
+	}
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back().Value.(*cacheEntry))
+	}
+}
+
+// remove must be called with c.mu held.
+func (c *queryCache) remove(e *cacheEntry) {
+	elem, ok := c.entries[e.key]
+	if !ok || elem.Value != e {
+		return
+	}
+	c.lru.Remove(elem)
+	delete(c.entries, e.key)
+	delete(c.wildcard, e)
+	for _, pred := range e.preds {
+		delete(c.byPred[pred], e)
+		if len(c.byPred[pred]) == 0 {
+			delete(c.byPred, pred)
+		}
+	}
+}
+
+// invalidate removes the responses of the queries that may use any of the
+// predicates, given in the format of TxnContext.Preds.
+func (c *queryCache) invalidate(preds []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	for e := range c.wildcard {
+		c.remove(e)
+	}
+	for _, pred := range preds {
+		for e := range c.byPred[predicateName(pred)] {
+			c.remove(e)
+		}
+	}
+}
+
+// clear removes all the responses, e.g. after a schema change.
+func (c *queryCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	c.reset()
+}
+
+// predicateName returns the name of a predicate in TxnContext.Preds. These are
+// of the form <group>-<predicate>, and the predicate may have an 8 byte namespace
+// prefix.
+func predicateName(pred string) string {
+	if i := strings.IndexByte(pred, '-'); i > 0 && strings.Trim(pred[:i], "0123456789") == "" {
+		pred = pred[i+1:]
+	}
+	if len(pred) > 8 && pred[0] == 0 {
+		pred = pred[8:]
+	}
+	return pred
+}
+
+// queryPredicates returns the names in a query, which include all the predicates
+// the query uses, or wildcard if the query can use any predicate as it expands
+// the predicates of types. String literals and comments are skipped.
+func queryPredicates(q string) (preds []string, wildcard bool) {
+	seen := make(map[string]struct{})
+	add := func(name string) {
+		if name == "expand" {
+			wildcard = true
+		}
+		if _, ok := seen[name]; !ok && name != "" {
+			seen[name] = struct{}{}
+			preds = append(preds, name)
+		}
+	}
+
+	for i := 0; i < len(q); {
+		switch c := q[i]; {
+		case c == '"':
+			i++
+			for i < len(q) && q[i] != '"' {
+				if q[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+		case c == '#':
+			for i < len(q) && q[i] != '\n' {
+				i++
+			}
+		case c == '<':
+			j := strings.IndexByte(q[i:], '>')
+			if j < 0 {
+				return preds, true
+			}
+			add(q[i+1 : i+j])
+			i += j + 1
+		case isNameChar(c):
+			j := i
+			for j < len(q) && isNameChar(q[j]) {
+				j++
+			}
+			add(q[i:j])
+			i = j
+		default:
+			i++
+		}
+	}
+	return preds, wildcard
+}
+
+func isNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '.' || c >= 0x80
+}
+
+// cachedQuery runs a query of a read-only transaction using the query cache.
+func (txn *Txn) cachedQuery(ctx context.Context, req *api.Request) (*api.Response, error) {
+	c := txn.dg.cache
+	user, ns := txn.dg.loginIdentity()
+	key := cacheKey(user, ns, req)
+
+	txn.mu.Lock()
+	first := txn.context.StartTs == 0 && txn.starting == nil && !txn.finished
+	if first {
+		if resp, ok := c.get(key); ok {
+			err := txn.mergeContext(resp.Txn)
+			txn.mu.Unlock()
+			if err != nil {
+				return nil, err
+			}
+			// Interceptors see the cached queries too, although they are not sent.
+			call := &Call{Method: "Query", Request: req, Txn: txn, Attempt: 1}
+			return intercept(ctx, txn.dg, call, func(context.Context) (*api.Response, error) {
+				return resp, nil
+			})
+		}
+	}
+	txn.mu.Unlock()
+
+	gen := c.generation()
+	resp, err := txn.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if first {
+		c.put(gen, key, req.Query, resp)
+	}
+	return resp, nil
+}
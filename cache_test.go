@@ -0,0 +1,222 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dgraph-io/dgo/v250"
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+// newCacheClient returns a client using the query cache, connected to a fake server
+// whose responses count the queries it has answered.
+func newCacheClient(t *testing.T, maxEntries int, ttl time.Duration,
+	opts ...dgo.ClientOption) (*dgo.Dgraph, *fakeDgraphServer) {
+
+	srv := &fakeDgraphServer{}
+	srv.query = func(_ context.Context, req *api.Request) (*api.Response, error) {
+		if len(req.Mutations) > 0 {
+			return &api.Response{Txn: &api.TxnContext{StartTs: 10, Preds: []string{"1-name"}}}, nil
+		}
+		json := fmt.Sprintf(`{"n": %d}`, srv.queries.Load())
+		return &api.Response{Json: []byte(json), Txn: &api.TxnContext{StartTs: 5}}, nil
+	}
+	srv.runDQL = func(_ context.Context, req *api.RunDQLRequest) (*api.Response, error) {
+		if req.ReadOnly {
+			return &api.Response{Json: []byte(`{}`), Txn: &api.TxnContext{StartTs: 5}}, nil
+		}
+		return &api.Response{Txn: &api.TxnContext{StartTs: 10, Preds: []string{"1-name"}}}, nil
+	}
+	addr, _ := startFakeServer(t, "127.0.0.1:0", nil, srv)
+
+	opts = append(opts,
+		dgo.WithGrpcOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		dgo.WithQueryCache(maxEntries, ttl))
+	dg, err := dgo.NewClient(addr, opts...)
+	require.NoError(t, err)
+	t.Cleanup(dg.Close)
+	return dg, srv
+}
+
+func TestQueryCacheOffline(t *testing.T) {
+	ctx := context.Background()
+	dg, srv := newCacheClient(t, 10, time.Minute)
+
+	query := func(q string, vars map[string]string) string {
+		resp, err := dg.NewReadOnlyTxn().QueryWithVars(ctx, q, vars)
+		require.NoError(t, err)
+		return string(resp.Json)
+	}
+	const byName = `{ q(func: has(name)) { name } }`
+	const byAge = `{ q(func: has(<age>)) { age } }`
+	const expand = `{ q(func: uid(0x1)) { expand(_all_) } }`
+
+	require.Equal(t, `{"n": 1}`, query(byName, nil))
+	require.Equal(t, `{"n": 1}`, query(byName, nil))
+	require.Equal(t, `{"n": 2}`, query(byName, map[string]string{"$a": "1"}))
+	require.Equal(t, `{"n": 2}`, query(byName, map[string]string{"$a": "1"}))
+	require.Equal(t, `{"n": 3}`, query(byAge, nil))
+	require.Equal(t, `{"n": 4}`, query(expand, nil))
+	require.EqualValues(t, 4, srv.queries.Load())
+
+	// The cached response carries the snapshot of the transaction.
+	txn := dg.NewReadOnlyTxn()
+	_, err := txn.Query(ctx, byAge)
+	require.NoError(t, err)
+	resp, err := txn.Query(ctx, byName)
+	require.NoError(t, err)
+	require.Equal(t, `{"n": 5}`, string(resp.Json))
+
+	// Queries of transactions that are not read-only bypass the cache.
+	txn = dg.NewTxn()
+	resp, err = txn.Query(ctx, byAge)
+	require.NoError(t, err)
+	require.Equal(t, `{"n": 6}`, string(resp.Json))
+	require.NoError(t, txn.Discard(ctx))
+
+	// Committing a change to name invalidates the queries using name, or any
+	// predicate with expand.
+	txn = dg.NewTxn()
+	_, err = txn.Mutate(ctx, &api.Mutation{SetNquads: []byte(`_:a <name> "Alice" .`)})
+	require.NoError(t, err)
+	require.Equal(t, `{"n": 1}`, query(byName, nil))
+	require.NoError(t, txn.Commit(ctx))
+	require.Equal(t, `{"n": 8}`, query(byName, nil))
+	require.Equal(t, `{"n": 3}`, query(byAge, nil))
+	require.Equal(t, `{"n": 9}`, query(expand, nil))
+
+	// So does a mutation committed right away.
+	_, err = dg.NewTxn().Mutate(ctx, &api.Mutation{
+		SetNquads: []byte(`_:a <name> "Bob" .`),
+		CommitNow: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, `{"n": 11}`, query(byName, nil))
+	require.Equal(t, `{"n": 3}`, query(byAge, nil))
+
+	// So do the DQL requests that are not read-only.
+	_, err = dg.RunDQL(ctx, `{ q(func: has(name)) { name } }`, dgo.WithReadOnly())
+	require.NoError(t, err)
+	require.Equal(t, `{"n": 11}`, query(byName, nil))
+	_, err = dg.RunDQL(ctx, `{ set { _:a <name> "Carol" . } }`)
+	require.NoError(t, err)
+	require.Equal(t, `{"n": 12}`, query(byName, nil))
+	require.Equal(t, `{"n": 3}`, query(byAge, nil))
+
+	// Schema changes invalidate all the queries.
+	require.NoError(t, dg.SetSchema(ctx, "age: int ."))
+	require.Equal(t, `{"n": 13}`, query(byAge, nil))
+}
+
+func TestQueryCacheEvictionOffline(t *testing.T) {
+	ctx := context.Background()
+	dg, _ := newCacheClient(t, 2, 100*time.Millisecond)
+
+	query := func(q string) string {
+		resp, err := dg.NewReadOnlyTxn().Query(ctx, q)
+		require.NoError(t, err)
+		return string(resp.Json)
+	}
+
+	require.Equal(t, `{"n": 1}`, query("{ a(func: has(a)) { a } }"))
+	require.Equal(t, `{"n": 2}`, query("{ b(func: has(b)) { b } }"))
+	require.Equal(t, `{"n": 1}`, query("{ a(func: has(a)) { a } }"))
+	require.Equal(t, `{"n": 3}`, query("{ c(func: has(c)) { c } }"))
+
+	// b was the least recently used response.
+	require.Equal(t, `{"n": 1}`, query("{ a(func: has(a)) { a } }"))
+	require.Equal(t, `{"n": 4}`, query("{ b(func: has(b)) { b } }"))
+
+	time.Sleep(150 * time.Millisecond)
+	require.Equal(t, `{"n": 5}`, query("{ b(func: has(b)) { b } }"))
+}
+
+func TestQueryCacheInterceptorOffline(t *testing.T) {
+	ctx := context.Background()
+	var calls int
+	var denied bool
+	guard := func(ctx context.Context, call *dgo.Call, invoke dgo.Invoker) (proto.Message, error) {
+		calls++
+		if denied {
+			return nil, errors.New("denied")
+		}
+		return invoke(ctx)
+	}
+	dg, srv := newCacheClient(t, 10, time.Minute, dgo.WithInterceptor(guard))
+
+	const q = `{ q(func: has(name)) { name } }`
+	for range 2 {
+		resp, err := dg.NewReadOnlyTxn().Query(ctx, q)
+		require.NoError(t, err)
+		require.Equal(t, `{"n": 1}`, string(resp.Json))
+	}
+	require.EqualValues(t, 1, srv.queries.Load())
+	require.Equal(t, 2, calls)
+
+	// Cached responses are not returned if an interceptor rejects the query.
+	denied = true
+	_, err := dg.NewReadOnlyTxn().Query(ctx, q)
+	require.EqualError(t, err, "denied")
+	require.Equal(t, 3, calls)
+}
+
+func TestQueryCacheOptions(t *testing.T) {
+	tests := []struct {
+		opt dgo.ClientOption
+		err string
+	}{
+		{opt: dgo.WithQueryCache(0, time.Minute), err: "invalid query cache size: 0"},
+		{opt: dgo.WithQueryCache(10, 0), err: "invalid query cache TTL: 0s"},
+	}
+
+	for _, tc := range tests {
+		_, err := dgo.NewClient("127.0.0.1:9180", tc.opt)
+		require.EqualError(t, err, tc.err)
+	}
+}
+
+func TestQueryCacheUsersOffline(t *testing.T) {
+	srv := &fakeDgraphServer{
+		login: func(_ context.Context, req *api.LoginRequest) (*api.Response, error) {
+			jwt, err := proto.Marshal(&api.Jwt{AccessJwt: req.Userid, RefreshJwt: "refresh"})
+			return &api.Response{Json: jwt}, err
+		},
+		query: func(ctx context.Context, req *api.Request) (*api.Response, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			json := fmt.Sprintf(`{"user": %q}`, md.Get("accessJwt")[0])
+			return &api.Response{Json: []byte(json), Txn: &api.TxnContext{StartTs: 5}}, nil
+		},
+	}
+	addr, _ := startFakeServer(t, "127.0.0.1:0", nil, srv)
+
+	dg, err := dgo.NewClient(addr,
+		dgo.WithGrpcOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		dgo.WithQueryCache(10, time.Minute))
+	require.NoError(t, err)
+	defer dg.Close()
+
+	// Users of the same namespace may be allowed to read different predicates.
+	ctx := context.Background()
+	for _, user := range []string{"admin", "reader", "admin", "reader"} {
+		h, err := dg.ForNamespace(ctx, 1, user, "password")
+		require.NoError(t, err)
+		resp, err := h.NewReadOnlyTxn().Query(ctx, `{ q(func: has(secret)) { secret } }`)
+		require.NoError(t, err)
+		require.JSONEq(t, fmt.Sprintf(`{"user": %q}`, user), string(resp.Json))
+	}
+	require.EqualValues(t, 2, srv.queries.Load())
+}
@@ -0,0 +1,176 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// certReloader serves the client certificate and CA certificates read from files,
+// reloading them when the files change. Files are checked at most once per interval
+// when a new TLS connection is established, so rotated certificates are picked up
+// by new connections without recreating the client.
+type certReloader struct {
+	caFile   string
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu        sync.Mutex
+	lastCheck time.Time
+	versions  map[string]fileVersion
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+}
+
+// fileVersion identifies the contents of a file for change detection.
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+func newCertReloader(caFile, certFile, keyFile string, interval time.Duration) (*certReloader, error) {
+	r := &certReloader{caFile: caFile, certFile: certFile, keyFile: keyFile, interval: interval}
+	versions, err := r.stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(versions); err != nil {
+		return nil, err
+	}
+	r.lastCheck = time.Now()
+	return r, nil
+}
+
+// config returns a copy of base that uses the current certificates.
+func (r *certReloader) config(base *tls.Config) *tls.Config {
+	r.maybeReload()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cfg := base.Clone()
+	if r.cert != nil {
+		cfg.Certificates = []tls.Certificate{*r.cert}
+	}
+	if r.pool != nil {
+		cfg.RootCAs = r.pool
+	}
+	return cfg
+}
+
+// rootCAs returns the current CA certificates, or nil if no CA file is reloaded.
+func (r *certReloader) rootCAs() *x509.CertPool {
+	r.maybeReload()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pool
+}
+
+// maybeReload reloads the certificates if the interval has passed since the
+// last check and any of the files has changed. If the files can't be loaded,
+// the previous certificates continue to be used and loading is retried after
+// the next interval.
+func (r *certReloader) maybeReload() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.lastCheck) < r.interval {
+		return
+	}
+	r.lastCheck = time.Now()
+
+	versions, err := r.stat()
+	if err != nil || !r.changed(versions) {
+		return
+	}
+	_ = r.load(versions)
+}
+
+func (r *certReloader) stat() (map[string]fileVersion, error) {
+	versions := make(map[string]fileVersion, 3)
+	for _, file := range []string{r.caFile, r.certFile, r.keyFile} {
+		if file == "" {
+			continue
+		}
+		fi, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat certificate file: %w", err)
+		}
+		versions[file] = fileVersion{modTime: fi.ModTime(), size: fi.Size()}
+	}
+	return versions, nil
+}
+
+func (r *certReloader) changed(versions map[string]fileVersion) bool {
+	for file, v := range versions {
+		if old, ok := r.versions[file]; !ok || !old.modTime.Equal(v.modTime) || old.size != v.size {
+			return true
+		}
+	}
+	return false
+}
+
+// load must be called with r.mu held, unless r is not yet in use.
+func (r *certReloader) load(versions map[string]fileVersion) error {
+	var cert *tls.Certificate
+	if r.certFile != "" {
+		c, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cert = &c
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		p, err := loadCertPool(r.caFile)
+		if err != nil {
+			return err
+		}
+		pool = p
+	}
+
+	r.cert, r.pool, r.versions = cert, pool, versions
+	return nil
+}
+
+// reloadingCreds are TLS transport credentials that use the certificates served by
+// a certReloader, building the TLS configuration afresh for every new connection.
+type reloadingCreds struct {
+	credentials.TransportCredentials
+
+	base     *tls.Config
+	reloader *certReloader
+}
+
+func newReloadingCreds(base *tls.Config, r *certReloader) credentials.TransportCredentials {
+	return &reloadingCreds{
+		TransportCredentials: credentials.NewTLS(base),
+		base:                 base,
+		reloader:             r,
+	}
+}
+
+func (c *reloadingCreds) ClientHandshake(ctx context.Context, authority string,
+	rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+
+	return credentials.NewTLS(c.reloader.config(c.base)).ClientHandshake(ctx, authority, rawConn)
+}
+
+func (c *reloadingCreds) Clone() credentials.TransportCredentials {
+	return newReloadingCreds(c.base.Clone(), c.reloader)
+}
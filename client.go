@@ -11,9 +11,11 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"net/http"
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc"
@@ -28,6 +30,11 @@ import (
 const (
 	cloudPort      = "443"
 	requestTimeout = 30 * time.Second
+
+	// accessTokenHeader is the HTTP header for the access JWT of a logged in user.
+	accessTokenHeader = "X-Dgraph-AccessToken"
+	// apiKeyHeader is the HTTP header for Dgraph Cloud API keys.
+	apiKeyHeader = "Dg-Auth"
 )
 
 // Dgraph is a transaction-aware client to a Dgraph cluster.
@@ -36,8 +43,36 @@ type Dgraph struct {
 	jwt      api.Jwt
 	conns    []*grpc.ClientConn
 	dc       []api.DgraphClient
+
+	// endpoints holds the address of each client in dc, if known.
+	endpoints []string
+	lbPolicy  LoadBalancingPolicy
+	next      atomic.Uint64
+
+	// httpAuth holds the credentials of the client that are also used for
+	// requests to the HTTP endpoints, see AuthHeaders.
+	httpAuth []httpAuth
+
+	// user and namespace are those the client is logged into, protected by jwtMutex.
+	user      string
+	namespace uint64
+	cache     *queryCache
+	hedger    *hedger
+
+	interceptors []Interceptor
 }
 
+// LoadBalancingPolicy determines how a client picks one of its endpoints for
+// each transaction or request.
+type LoadBalancingPolicy string
+
+const (
+	// LoadBalanceRandom picks an endpoint at random. This is the default.
+	LoadBalanceRandom LoadBalancingPolicy = "random"
+	// LoadBalanceRoundRobin picks the endpoints in turn.
+	LoadBalanceRoundRobin LoadBalancingPolicy = "roundrobin"
+)
+
 type authCreds struct {
 	token string
 }
@@ -52,6 +87,17 @@ func (a *authCreds) RequireTransportSecurity() bool {
 	return true
 }
 
+func (a *authCreds) setHTTPAuth(_ context.Context, h http.Header) error {
+	h.Set(apiKeyHeader, a.token)
+	return nil
+}
+
+// httpAuth is implemented by the credentials that can also authenticate requests
+// to the HTTP endpoints of a cluster.
+type httpAuth interface {
+	setHTTPAuth(ctx context.Context, h http.Header) error
+}
+
 // NewDgraphClient creates a new Dgraph (client) for interacting with Alphas.
 // The client is backed by multiple connections to the same or different
 // servers in a cluster.
@@ -124,14 +170,22 @@ func (d *Dgraph) login(ctx context.Context, userid string, password string,
 		Password:  password,
 		Namespace: namespace,
 	}
-	resp, err := dc.Login(ctx, loginRequest)
+	resp, err := d.loginCall(ctx, dc, loginRequest)
 	if err != nil {
 		return err
 	}
 
+	d.user, d.namespace = userid, namespace
 	return proto.Unmarshal(resp.Json, &d.jwt)
 }
 
+// loginIdentity returns the user and the namespace the client is logged into.
+func (d *Dgraph) loginIdentity() (string, uint64) {
+	d.jwtMutex.RLock()
+	defer d.jwtMutex.RUnlock()
+	return d.user, d.namespace
+}
+
 // GetJwt returns back the JWT for the dgraph client.
 //
 // Deprecated
@@ -162,15 +216,7 @@ func (d *Dgraph) LoginIntoNamespace(ctx context.Context,
 //
 // Use DropAll, DropData, DropPredicate, DropType, SetSchema instead for better readability.
 func (d *Dgraph) Alter(ctx context.Context, op *api.Operation) error {
-	dc := d.anyClient()
-	_, err := dc.Alter(d.getContext(ctx), op)
-	if isJwtExpired(err) {
-		if err := d.retryLogin(ctx); err != nil {
-			return err
-		}
-		_, err = dc.Alter(d.getContext(ctx), op)
-	}
-	return err
+	return d.doAlter(ctx, op)
 }
 
 // Relogin relogin the current client using the refresh token. This can be used when the
@@ -191,7 +237,7 @@ func (d *Dgraph) retryLogin(ctx context.Context) error {
 	loginRequest := &api.LoginRequest{
 		RefreshToken: d.jwt.RefreshJwt,
 	}
-	resp, err := dc.Login(ctx, loginRequest)
+	resp, err := d.loginCall(ctx, dc, loginRequest)
 	if err != nil {
 		return err
 	}
@@ -199,6 +245,16 @@ func (d *Dgraph) retryLogin(ctx context.Context) error {
 	return proto.Unmarshal(resp.Json, &d.jwt)
 }
 
+// loginCall sends a login request through the interceptors of the client.
+func (d *Dgraph) loginCall(ctx context.Context, dc api.DgraphClient,
+	req *api.LoginRequest) (*api.Response, error) {
+
+	call := &Call{Method: "Login", Request: req, Attempt: 1}
+	return intercept(ctx, d, call, func(ctx context.Context) (*api.Response, error) {
+		return dc.Login(ctx, req)
+	})
+}
+
 func (d *Dgraph) getContext(ctx context.Context) context.Context {
 	d.jwtMutex.RLock()
 	defer d.jwtMutex.RUnlock()
@@ -215,6 +271,26 @@ func (d *Dgraph) getContext(ctx context.Context) context.Context {
 	return ctx
 }
 
+// AuthHeaders returns the HTTP headers that authenticate requests to the HTTP
+// endpoints of the cluster, such as /graphql and /admin, using the credentials of
+// the client: the access JWT of the logged in user, and the API key or bearer token
+// if any. This allows using the same credentials for the gRPC and HTTP APIs.
+func (d *Dgraph) AuthHeaders(ctx context.Context) (http.Header, error) {
+	h := make(http.Header)
+	for _, a := range d.httpAuth {
+		if err := a.setHTTPAuth(ctx, h); err != nil {
+			return nil, err
+		}
+	}
+
+	d.jwtMutex.RLock()
+	defer d.jwtMutex.RUnlock()
+	if len(d.jwt.AccessJwt) > 0 {
+		h.Set(accessTokenHeader, d.jwt.AccessJwt)
+	}
+	return h, nil
+}
+
 // isJwtExpired returns true if the error indicates that the jwt has expired.
 func isJwtExpired(err error) bool {
 	if err == nil {
@@ -226,8 +302,27 @@ func isJwtExpired(err error) bool {
 }
 
 func (d *Dgraph) anyClient() api.DgraphClient {
-	//nolint:gosec
-	return d.dc[rand.Intn(len(d.dc))]
+	return d.dc[d.anyClientIndex()]
+}
+
+func (d *Dgraph) anyClientIndex() int {
+	var i int
+	if d.lbPolicy == LoadBalanceRoundRobin {
+		//nolint:gosec
+		i = int((d.next.Add(1) - 1) % uint64(len(d.dc)))
+	} else {
+		//nolint:gosec
+		i = rand.Intn(len(d.dc))
+	}
+
+	// Avoid the endpoints whose circuit breaker is open, if possible.
+	for j := range len(d.dc) {
+		k := (i + j) % len(d.dc)
+		if b, ok := d.dc[k].(*breakerClient); !ok || !b.isOpen() {
+			return k
+		}
+	}
+	return i
 }
 
 // DeleteEdges sets the edges corresponding to predicates
@@ -9,6 +9,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -16,6 +17,11 @@ import (
 	api "github.com/dgraph-io/dgo/v250/protos/api"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 // This test only ensures that connection strings are parsed correctly.
@@ -41,7 +47,7 @@ func TestOpen(t *testing.T) {
 	require.ErrorContains(t, err, "first record does not look like a TLS handshake")
 
 	_, err = dgo.Open("dgraph://localhost:9180?sslmode=prefer")
-	require.ErrorContains(t, err, "invalid SSL mode: prefer (must be one of disable, require, verify-ca)")
+	require.ErrorContains(t, err, "invalid SSL mode: prefer (must be one of disable, require, verify-ca, verify-full)")
 
 	_, err = dgo.Open("dgraph://localhost:9180?sslmode=disable&bearertoken=abc")
 	require.ErrorContains(t, err, "grpc: the credentials require transport level security")
@@ -237,3 +243,123 @@ func TestREADME(t *testing.T) {
 	require.NoError(t, json.Unmarshal(resp.Json, &m))
 	require.Equal(t, m["alice"][0].Age, 31)
 }
+
+func TestOpenTransportParams(t *testing.T) {
+	var err error
+
+	_, err = dgo.Open("dgraph://localhost:9180?timeout=soon")
+	require.ErrorContains(t, err, `invalid timeout: time: invalid duration "soon"`)
+
+	_, err = dgo.Open("dgraph://localhost:9180?connecttimeout=-1s")
+	require.ErrorContains(t, err, "invalid connecttimeout: must be positive")
+
+	_, err = dgo.Open("dgraph://localhost:9180?keepalivetimeout=10s")
+	require.ErrorContains(t, err, "invalid connection string: keepalivetimeout requires keepalive")
+
+	_, err = dgo.Open("dgraph://localhost:9180?keepalive=30s&keepalivetimeout=0s")
+	require.ErrorContains(t, err, "invalid keepalivetimeout: must be positive")
+
+	_, err = dgo.Open("dgraph://localhost:9180?maxrecvmsgsize=64MB")
+	require.ErrorContains(t, err, `invalid maxrecvmsgsize: strconv.Atoi: parsing "64MB": invalid syntax`)
+
+	_, err = dgo.Open("dgraph://localhost:9180?maxsendmsgsize=0")
+	require.ErrorContains(t, err, "invalid maxsendmsgsize: must be positive")
+
+	_, err = dgo.Open("dgraph://localhost:9180?compression=zstd")
+	require.ErrorContains(t, err, "invalid compression: zstd (must be one of none, gzip)")
+
+	_, err = dgo.Open("dgraph://localhost:9180?maxretries=-1")
+	require.ErrorContains(t, err, "invalid maxretries: must be positive")
+}
+
+func TestOpenTransportParamsOffline(t *testing.T) {
+	const size = 5 << 20
+	srv := &fakeDgraphServer{query: func(ctx context.Context, req *api.Request) (*api.Response, error) {
+		if req.Query == "slow" {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return &api.Response{Json: make([]byte, size)}, nil
+	}}
+	addr, _ := startFakeServer(t, "127.0.0.1:0", nil, srv)
+	ctx := context.Background()
+
+	dg, err := dgo.Open(fmt.Sprintf("dgraph://%s", addr))
+	require.NoError(t, err)
+	defer dg.Close()
+	_, err = dg.NewReadOnlyTxn().Query(ctx, "large")
+	require.ErrorContains(t, err, "ResourceExhausted")
+
+	dg, err = dgo.Open(fmt.Sprintf("dgraph://%s?maxrecvmsgsize=%d&maxsendmsgsize=%d&compression=gzip"+
+		"&keepalive=30s&keepalivetimeout=10s&connecttimeout=5s&maxretries=2&timeout=1s", addr, 2*size, size))
+	require.NoError(t, err)
+	defer dg.Close()
+	resp, err := dg.NewReadOnlyTxn().Query(ctx, "large")
+	require.NoError(t, err)
+	require.Len(t, resp.Json, size)
+
+	start := time.Now()
+	_, err = dg.NewReadOnlyTxn().Query(ctx, "slow")
+	require.ErrorContains(t, err, "DeadlineExceeded")
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	// The default timeout doesn't override the deadline of the caller.
+	deadlineCtx, cancel := context.WithTimeout(ctx, 1500*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	_, err = dg.NewReadOnlyTxn().Query(deadlineCtx, "slow")
+	require.ErrorContains(t, err, "DeadlineExceeded")
+	require.GreaterOrEqual(t, time.Since(start), 1500*time.Millisecond)
+}
+
+func TestRetryPolicyOffline(t *testing.T) {
+	var failures atomic.Int64
+	srv := &fakeDgraphServer{
+		query: func(context.Context, *api.Request) (*api.Response, error) {
+			if failures.Add(-1) >= 0 {
+				return nil, status.Error(codes.Unavailable, "restarting")
+			}
+			return &api.Response{Json: []byte(`{}`)}, nil
+		},
+		login: func(context.Context, *api.LoginRequest) (*api.Response, error) {
+			if failures.Add(-1) >= 0 {
+				return nil, status.Error(codes.Unavailable, "restarting")
+			}
+			jwt, err := proto.Marshal(&api.Jwt{AccessJwt: "access", RefreshJwt: "refresh"})
+			return &api.Response{Json: jwt}, err
+		},
+	}
+	addr, _ := startFakeServer(t, "127.0.0.1:0", nil, srv)
+	ctx := context.Background()
+	mutate := func(dg *dgo.Dgraph) error {
+		_, err := dg.NewTxn().Mutate(ctx, &api.Mutation{SetNquads: []byte(`_:a <name> "A" .`),
+			CommitNow: true})
+		return err
+	}
+
+	// Queries may carry mutations, so they are not retried by default.
+	failures.Store(1)
+	dg, err := dgo.NewClient(addr,
+		dgo.WithGrpcOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		dgo.WithACLCreds("groot", "password"),
+		dgo.WithRetryPolicy(dgo.RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond}))
+	require.NoError(t, err)
+	defer dg.Close()
+	require.EqualValues(t, -1, failures.Load())
+
+	failures.Store(1)
+	require.Equal(t, codes.Unavailable, status.Code(mutate(dg)))
+
+	dg, err = dgo.NewClient(addr,
+		dgo.WithGrpcOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		dgo.WithRetryPolicy(dgo.RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond,
+			Methods: []string{"Query"}}))
+	require.NoError(t, err)
+	defer dg.Close()
+	failures.Store(2)
+	require.NoError(t, mutate(dg))
+
+	_, err = dgo.NewClient(addr, dgo.WithRetryPolicy(dgo.RetryPolicy{MaxRetries: 2,
+		Methods: []string{"Upsert"}}))
+	require.EqualError(t, err, `invalid retried method: "Upsert"`)
+}
@@ -0,0 +1,316 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+// schemaPredicate is a predicate in the response to a schema query.
+type schemaPredicate struct {
+	Predicate  string   `json:"predicate"`
+	Type       string   `json:"type"`
+	Index      bool     `json:"index"`
+	Tokenizer  []string `json:"tokenizer"`
+	Reverse    bool     `json:"reverse"`
+	Count      bool     `json:"count"`
+	List       bool     `json:"list"`
+	Upsert     bool     `json:"upsert"`
+	Lang       bool     `json:"lang"`
+	NoConflict bool     `json:"no_conflict"`
+	Unique     bool     `json:"unique"`
+	IndexSpecs []struct {
+		Name    string `json:"name"`
+		Options []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"options"`
+	} `json:"index_specs"`
+}
+
+// schemaType is a type in the response to a schema query.
+type schemaType struct {
+	Name   string `json:"name"`
+	Fields []struct {
+		Name string `json:"name"`
+	} `json:"fields"`
+}
+
+// String returns the predicate in the schema language.
+func (p *schemaPredicate) String() string {
+	var b strings.Builder
+	if p.List {
+		fmt.Fprintf(&b, "<%s>: [%s]", p.Predicate, p.Type)
+	} else {
+		fmt.Fprintf(&b, "<%s>: %s", p.Predicate, p.Type)
+	}
+
+	var indexes []string
+	if len(p.IndexSpecs) > 0 {
+		for _, spec := range p.IndexSpecs {
+			var opts []string
+			for _, opt := range spec.Options {
+				opts = append(opts, fmt.Sprintf("%s: %q", opt.Key, opt.Value))
+			}
+			indexes = append(indexes, spec.Name+"("+strings.Join(opts, ", ")+")")
+		}
+	} else {
+		indexes = p.Tokenizer
+	}
+	if p.Index && len(indexes) > 0 {
+		fmt.Fprintf(&b, " @index(%s)", strings.Join(indexes, ", "))
+	}
+	for _, d := range []struct {
+		set       bool
+		directive string
+	}{
+		{p.Reverse, "@reverse"},
+		{p.Count, "@count"},
+		{p.Upsert, "@upsert"},
+		{p.Lang, "@lang"},
+		{p.NoConflict, "@noconflict"},
+		{p.Unique, "@unique"},
+	} {
+		if d.set {
+			b.WriteString(" " + d.directive)
+		}
+	}
+	b.WriteString(" .")
+	return b.String()
+}
+
+// String returns the type in the schema language.
+func (t *schemaType) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "type <%s> {\n", t.Name)
+	for _, f := range t.Fields {
+		fmt.Fprintf(&b, "\t<%s>\n", f.Name)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// isInternal reports whether the predicate or type is managed by Dgraph itself.
+func isInternal(name string) bool {
+	return strings.HasPrefix(name, "dgraph.")
+}
+
+// CopyNamespace copies the schema and data of the namespace that src is logged into
+// to the namespace that dst is logged into, e.g. using handles returned by
+// Dgraph.ForNamespace. The schema is applied to dst first. The data is then read
+// predicate by predicate from a single snapshot of src using paginated read-only
+// queries, and written to dst in batches of batchSize nodes, each in its own
+// transaction. The data already in dst is kept.
+//
+// Predicates and types managed by Dgraph, except for dgraph.type, aren't copied.
+// Neither are the values of password predicates, as they can't be read. The
+// mapping from the uids of src to the uids of dst is kept in memory while copying.
+func CopyNamespace(ctx context.Context, src, dst *Dgraph, batchSize int) error {
+	if batchSize <= 0 {
+		return fmt.Errorf("invalid batch size: %d", batchSize)
+	}
+
+	preds, schema, err := readSchema(ctx, src)
+	if err != nil {
+		return err
+	}
+	if schema != "" {
+		if err := dst.SetSchema(ctx, schema); err != nil {
+			return fmt.Errorf("error applying schema: %w", err)
+		}
+	}
+
+	c := &namespaceCopier{
+		src:       src.NewReadOnlyTxn(),
+		dst:       dst,
+		batchSize: batchSize,
+		uids:      make(map[string]string),
+	}
+	preds = append(preds, &schemaPredicate{Predicate: "dgraph.type", Type: "string", List: true})
+	for _, pred := range preds {
+		if pred.Type == "password" {
+			continue
+		}
+		if err := c.copyPredicate(ctx, pred); err != nil {
+			return fmt.Errorf("error copying predicate %s: %w", pred.Predicate, err)
+		}
+	}
+	return nil
+}
+
+// readSchema returns the predicates of the schema, along with the schema in the
+// schema language, excluding the predicates and types managed by Dgraph.
+func readSchema(ctx context.Context, dg *Dgraph) ([]*schemaPredicate, string, error) {
+	resp, err := dg.NewReadOnlyTxn().Query(ctx, `schema {}`)
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading schema: %w", err)
+	}
+
+	var s struct {
+		Schema []*schemaPredicate `json:"schema"`
+		Types  []*schemaType      `json:"types"`
+	}
+	if err := json.Unmarshal(resp.Json, &s); err != nil {
+		return nil, "", fmt.Errorf("error parsing schema: %w", err)
+	}
+
+	var preds []*schemaPredicate
+	var lines []string
+	for _, p := range s.Schema {
+		if isInternal(p.Predicate) {
+			continue
+		}
+		preds = append(preds, p)
+		lines = append(lines, p.String())
+	}
+	for _, t := range s.Types {
+		if isInternal(t.Name) {
+			continue
+		}
+		lines = append(lines, t.String())
+	}
+	return preds, strings.Join(lines, "\n"), nil
+}
+
+type namespaceCopier struct {
+	src       *Txn
+	dst       *Dgraph
+	batchSize int
+	// uids maps the uids of src to the uids of the nodes created in dst.
+	uids map[string]string
+}
+
+// predicateQuery returns a query for Txn.QueryPages that returns the nodes that have
+// the predicate in the block q, along with all of the values of the predicate and
+// their facets, or the values in all languages for predicates with @lang.
+func predicateQuery(pred *schemaPredicate) string {
+	field := "<" + pred.Predicate + ">"
+	switch {
+	case pred.Type == "uid":
+		field += " @facets { uid }"
+	case pred.Lang:
+		field += "@* @facets"
+	default:
+		field += " @facets"
+	}
+	return fmt.Sprintf(`query q($first: int, $after: string) {
+		q(func: has(<%s>), first: $first, after: $after) {
+			uid
+			%s
+		}
+	}`, pred.Predicate, field)
+}
+
+func (c *namespaceCopier) copyPredicate(ctx context.Context, pred *schemaPredicate) error {
+	for resp, err := range c.src.QueryPages(ctx, predicateQuery(pred), nil, "q", c.batchSize) {
+		if err != nil {
+			return err
+		}
+		if err := c.copyPage(ctx, pred, resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *namespaceCopier) copyPage(ctx context.Context, pred *schemaPredicate, resp *api.Response) error {
+	var nodes []map[string]any
+	for node, err := range IterBlock[map[string]json.RawMessage](resp, "q") {
+		if err != nil {
+			return err
+		}
+		obj := make(map[string]any, len(node))
+		for key, value := range node {
+			switch {
+			case key == "uid":
+				var uid string
+				if err := json.Unmarshal(value, &uid); err != nil {
+					return err
+				}
+				obj[key] = c.ref(uid)
+			case key == pred.Predicate && pred.Type == "uid":
+				targets, err := c.targets(pred, value)
+				if err != nil {
+					return err
+				}
+				obj[key] = targets
+			case pred.Type == "float32vector" && bytes.HasPrefix(value, []byte("[")):
+				// Vectors are returned as arrays, but must be set as strings.
+				obj[key] = string(value)
+			default:
+				obj[key] = value
+			}
+		}
+		nodes = append(nodes, obj)
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	setJSON, err := json.Marshal(nodes)
+	if err != nil {
+		return err
+	}
+	out, err := c.dst.NewTxn().Mutate(ctx, &api.Mutation{SetJson: setJSON, CommitNow: true})
+	if err != nil {
+		return err
+	}
+	for name, uid := range out.Uids {
+		if srcUID, ok := strings.CutPrefix(name, "n"); ok {
+			c.uids[srcUID] = uid
+		}
+	}
+	return nil
+}
+
+// targets returns the nodes of a uid predicate with their uids mapped to dst.
+func (c *namespaceCopier) targets(pred *schemaPredicate, value json.RawMessage) (any, error) {
+	var nodes []map[string]json.RawMessage
+	if bytes.HasPrefix(value, []byte("[")) {
+		if err := json.Unmarshal(value, &nodes); err != nil {
+			return nil, err
+		}
+	} else {
+		var node map[string]json.RawMessage
+		if err := json.Unmarshal(value, &node); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	targets := make([]map[string]any, 0, len(nodes))
+	for _, node := range nodes {
+		target := make(map[string]any, len(node))
+		for key, value := range node {
+			target[key] = value
+		}
+		var uid string
+		if err := json.Unmarshal(node["uid"], &uid); err != nil {
+			return nil, err
+		}
+		target["uid"] = c.ref(uid)
+		targets = append(targets, target)
+	}
+	if !pred.List && len(targets) == 1 {
+		return targets[0], nil
+	}
+	return targets, nil
+}
+
+// ref returns the uid in dst of the node with the given uid in src, or a blank
+// node that is mapped to it once the batch is written.
+func (c *namespaceCopier) ref(uid string) string {
+	if dstUID, ok := c.uids[uid]; ok {
+		return dstUID
+	}
+	return "_:n" + uid
+}
@@ -0,0 +1,176 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgo/v250"
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+func TestCopyNamespaceOffline(t *testing.T) {
+	pages := map[string]string{
+		"name":        `[{"uid": "0x1", "name": "Alice", "name@fr": "Alicé"}, {"uid": "0x2", "name": "Bob"}]`,
+		"friend":      `[{"uid": "0x1", "friend": [{"uid": "0x2", "friend|since": 2020}]}]`,
+		"dgraph.type": `[{"uid": "0x1", "dgraph.type": ["Person"]}, {"uid": "0x3", "dgraph.type": ["Person"]}]`,
+	}
+	var startTs []uint64
+	src := dgo.NewDgraphClient(&fakeDgraphClient{
+		query: func(_ context.Context, req *api.Request) (*api.Response, error) {
+			startTs = append(startTs, req.StartTs)
+			if req.Query == "schema {}" {
+				return &api.Response{Json: []byte(`{
+					"schema": [
+						{"predicate": "dgraph.type", "type": "string", "index": true, "tokenizer": ["exact"], "list": true},
+						{"predicate": "name", "type": "string", "index": true, "tokenizer": ["exact", "term"], "lang": true},
+						{"predicate": "friend", "type": "uid", "list": true, "reverse": true, "count": true}
+					],
+					"types": [
+						{"name": "Person", "fields": [{"name": "name"}, {"name": "friend"}]},
+						{"name": "dgraph.graphql", "fields": [{"name": "dgraph.graphql.schema"}]}
+					]
+				}`)}, nil
+			}
+			page := "[]"
+			if req.Vars["$after"] == "0x0" {
+				for pred, nodes := range pages {
+					if strings.Contains(req.Query, "has(<"+pred+">)") {
+						page = nodes
+					}
+				}
+			}
+			return &api.Response{
+				Json: []byte(`{"q": ` + page + `}`),
+				Txn:  &api.TxnContext{StartTs: 42},
+			}, nil
+		},
+	})
+
+	var schema string
+	var mutations []string
+	var next int
+	dst := dgo.NewDgraphClient(&fakeDgraphClient{
+		alter: func(_ context.Context, op *api.Operation) (*api.Payload, error) {
+			schema = op.Schema
+			return &api.Payload{}, nil
+		},
+		query: func(_ context.Context, req *api.Request) (*api.Response, error) {
+			require.True(t, req.CommitNow)
+			setJSON := string(req.Mutations[0].SetJson)
+			mutations = append(mutations, setJSON)
+
+			var nodes []map[string]any
+			require.NoError(t, json.Unmarshal([]byte(setJSON), &nodes))
+			uids := map[string]string{}
+			for _, node := range nodes {
+				if uid := node["uid"].(string); strings.HasPrefix(uid, "_:") {
+					next++
+					uids[uid[2:]] = fmt.Sprintf("0x%x", 0x100+next)
+				}
+			}
+			return &api.Response{Uids: uids}, nil
+		},
+	})
+
+	ctx := context.Background()
+	require.NoError(t, dgo.CopyNamespace(ctx, src, dst, 2))
+
+	require.Equal(t, `<name>: string @index(exact, term) @lang .
+<friend>: [uid] @reverse @count .
+type <Person> {
+	<name>
+	<friend>
+}`, schema)
+
+	require.Len(t, mutations, 3)
+	require.JSONEq(t, `[
+		{"uid": "_:n0x1", "name": "Alice", "name@fr": "Alicé"},
+		{"uid": "_:n0x2", "name": "Bob"}
+	]`, mutations[0])
+	require.JSONEq(t, `[{"uid": "0x101", "friend": [{"uid": "0x102", "friend|since": 2020}]}]`, mutations[1])
+	require.JSONEq(t, `[
+		{"uid": "0x101", "dgraph.type": ["Person"]},
+		{"uid": "_:n0x3", "dgraph.type": ["Person"]}
+	]`, mutations[2])
+
+	// All the data is read from the snapshot of the first data query.
+	require.Equal(t, []uint64{0, 0, 42, 42, 42, 42}, startTs)
+}
+
+func TestCopyNamespaceErrors(t *testing.T) {
+	dg := dgo.NewDgraphClient(&fakeDgraphClient{})
+	err := dgo.CopyNamespace(context.Background(), dg, dg, 0)
+	require.ErrorContains(t, err, "invalid batch size: 0")
+}
+
+func TestCloneTenant(t *testing.T) {
+	dg, cancel := getDgraphClient()
+	defer cancel()
+
+	ctx := context.Background()
+	require.NoError(t, dg.DropAll(ctx))
+	m, err := dgo.NewTenantManager(dg, dgo.WithTenantCredentials("groot", "tenantpass"))
+	require.NoError(t, err)
+
+	_, err = m.CreateTenant(ctx, "template", &dgo.TenantSpec{
+		Schema: `
+			name: string @index(exact) @lang .
+			friend: [uid] @reverse .
+			type Person {
+				name
+				friend
+			}`,
+		Seed: []*api.Mutation{{SetNquads: []byte(`
+			_:a <name> "Alice" .
+			_:a <name> "Alicé"@fr .
+			_:a <dgraph.type> "Person" .
+			_:b <name> "Bob" .
+			_:b <dgraph.type> "Person" .
+			_:c <name> "Carol" .
+			_:a <friend> _:b (since=2020) .
+			_:a <friend> _:c .
+			_:c <friend> _:b .
+		`)}},
+	})
+	require.NoError(t, err)
+	defer func() { _ = m.DropTenant(ctx, "template") }()
+
+	sandbox, err := m.CloneTenant(ctx, "template", "sandbox", 2)
+	require.NoError(t, err)
+	defer func() { _ = m.DropTenant(ctx, "sandbox") }()
+
+	sdg, err := m.Connect(ctx, sandbox)
+	require.NoError(t, err)
+	resp, err := sdg.NewReadOnlyTxn().Query(ctx, `{
+		q(func: eq(name, "Alice")) {
+			name@fr
+			dgraph.type
+			friend @facets(since) (orderasc: name) {
+				name
+				~friend (orderasc: name) { name }
+			}
+		}
+	}`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"q": [{
+		"name@fr": "Alicé",
+		"dgraph.type": ["Person"],
+		"friend": [
+			{"name": "Bob", "friend|since": 2020, "~friend": [{"name": "Alice"}, {"name": "Carol"}]},
+			{"name": "Carol", "~friend": [{"name": "Alice"}]}
+		]
+	}]}`, string(resp.Json))
+
+	_, err = m.CloneTenant(ctx, "missing", "other", 2)
+	require.ErrorIs(t, err, dgo.ErrTenantNotFound)
+}
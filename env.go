@@ -0,0 +1,249 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Environment variables read by OpenFromEnv and WithEnvCredentials. Each of them
+// can instead be provided in a file, e.g. a Docker or Kubernetes secret, by setting
+// the variable with the "_FILE" suffix to the path of the file.
+const (
+	EnvConnectionString = "DGRAPH_CONNECTION_STRING"
+	EnvUser             = "DGRAPH_USER"
+	EnvPassword         = "DGRAPH_PASSWORD"
+	EnvAPIKey           = "DGRAPH_API_KEY"
+	EnvBearerToken      = "DGRAPH_BEARER_TOKEN"
+
+	envFileSuffix = "_FILE"
+)
+
+// OpenFromEnv is like Open, but reads the connection string from the
+// DGRAPH_CONNECTION_STRING environment variable, or the file named by
+// DGRAPH_CONNECTION_STRING_FILE. The credentials can be provided separately from
+// the connection string, using the environment variables read by WithEnvCredentials,
+// so that they don't need to be embedded in it. For example:
+//
+//	DGRAPH_CONNECTION_STRING=dgraph://groot@localhost:9080
+//	DGRAPH_PASSWORD_FILE=/run/secrets/dgraph-password
+//
+// It is an error to provide the same credential in both the connection string and
+// the environment. The given options are applied after the ones derived from the
+// connection string. Errors never contain the values of the secrets.
+func OpenFromEnv(opts ...ClientOption) (*Dgraph, error) {
+	connStr, err := readEnvSecret(EnvConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	if connStr == "" {
+		return nil, fmt.Errorf("neither %s nor %s is set", EnvConnectionString, EnvConnectionString+envFileSuffix)
+	}
+	cs, err := parseConnString(connStr)
+	if err != nil {
+		return nil, err
+	}
+	ec, err := loadEnvCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	username, password := cs.username, cs.password
+	switch {
+	case ec.username != "" && username != "":
+		return nil, fmt.Errorf("username is set in both the connection string and %s", EnvUser)
+	case ec.password != "" && password != "":
+		return nil, fmt.Errorf("password is set in both the connection string and %s", EnvPassword)
+	case ec.hasAPIKey() && cs.hasAPIKey:
+		return nil, fmt.Errorf("API key is set in both the connection string and %s", EnvAPIKey)
+	case ec.hasBearerToken() && cs.hasBearerToken:
+		return nil, fmt.Errorf("bearer token is set in both the connection string and %s", EnvBearerToken)
+	case (ec.hasAPIKey() || cs.hasAPIKey) && (ec.hasBearerToken() || cs.hasBearerToken):
+		return nil, errors.New("only one of API key or bearer token can be provided")
+	}
+	if ec.username != "" {
+		username = ec.username
+	}
+	if ec.password != "" {
+		password = ec.password
+	}
+
+	allOpts := append(cs.opts, ec.tokenOptions()...)
+	if cs.hasUser || username != "" || password != "" {
+		if username == "" || password == "" {
+			return nil, errors.New("invalid connection string: both username and password must be provided")
+		}
+		allOpts = append(allOpts, WithACLCreds(username, password))
+	}
+	return NewRoundRobinClient(cs.hosts, append(allOpts, opts...)...)
+}
+
+// WithEnvCredentials uses the credentials provided by the following environment
+// variables, each of which can instead be read from a file using the "_FILE" suffix:
+//
+//   - DGRAPH_USER and DGRAPH_PASSWORD for ACL authentication
+//   - DGRAPH_API_KEY for authentication with an API key
+//   - DGRAPH_BEARER_TOKEN for authentication with a Bearer Token
+//
+// The password is read once, when the client is created. The API key and bearer
+// token files are read again when they change, so that rotated secrets are used
+// without recreating the client.
+func WithEnvCredentials() ClientOption {
+	return func(o *clientOptions) error {
+		ec, err := loadEnvCredentials()
+		if err != nil {
+			return err
+		}
+		if ec.hasAPIKey() && ec.hasBearerToken() {
+			return fmt.Errorf("only one of %s or %s can be set", EnvAPIKey, EnvBearerToken)
+		}
+		if ec.username != "" || ec.password != "" {
+			if ec.username == "" || ec.password == "" {
+				return fmt.Errorf("both %s and %s must be set", EnvUser, EnvPassword)
+			}
+			if err := WithACLCreds(ec.username, ec.password)(o); err != nil {
+				return err
+			}
+		}
+		for _, opt := range ec.tokenOptions() {
+			if err := opt(o); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// WithACLCredsFile is like WithACLCreds, but reads the password from the given file,
+// such as a mounted Kubernetes secret. A trailing newline is removed from the password.
+func WithACLCredsFile(username, passwordFile string) ClientOption {
+	return func(o *clientOptions) error {
+		password, err := readSecretFile(passwordFile)
+		if err != nil {
+			return fmt.Errorf("failed to read password file: %w", err)
+		}
+		return WithACLCreds(username, password)(o)
+	}
+}
+
+// WithDgraphAPIKeyFile is like WithDgraphAPIKey, but reads the API key from the given
+// file. The file is read again whenever it changes.
+func WithDgraphAPIKeyFile(path string) ClientOption {
+	return withTokenFile(path, WithAPIKeySource)
+}
+
+// WithBearerTokenFile is like WithBearerToken, but reads the token from the given
+// file. The file is read again whenever it changes.
+func WithBearerTokenFile(path string) ClientOption {
+	return withTokenFile(path, WithTokenSource)
+}
+
+// withTokenFile reads the file once so that a missing or empty file is reported
+// when the client is created rather than on the first request.
+func withTokenFile(path string, withSource func(TokenSource) ClientOption) ClientOption {
+	return func(o *clientOptions) error {
+		src := NewFileTokenSource(path)
+		if _, err := src.Token(context.Background()); err != nil {
+			return err
+		}
+		return withSource(src)(o)
+	}
+}
+
+// envCredentials holds the credentials read from the environment.
+type envCredentials struct {
+	username        string
+	password        string
+	apiKey          string
+	apiKeyFile      string
+	bearerToken     string
+	bearerTokenFile string
+}
+
+func loadEnvCredentials() (*envCredentials, error) {
+	var ec envCredentials
+	var err error
+	if ec.username, err = readEnvSecret(EnvUser); err != nil {
+		return nil, err
+	}
+	if ec.password, err = readEnvSecret(EnvPassword); err != nil {
+		return nil, err
+	}
+	if ec.apiKey, ec.apiKeyFile, err = lookupEnvSecret(EnvAPIKey); err != nil {
+		return nil, err
+	}
+	if ec.bearerToken, ec.bearerTokenFile, err = lookupEnvSecret(EnvBearerToken); err != nil {
+		return nil, err
+	}
+	return &ec, nil
+}
+
+func (ec *envCredentials) hasAPIKey() bool {
+	return ec.apiKey != "" || ec.apiKeyFile != ""
+}
+
+func (ec *envCredentials) hasBearerToken() bool {
+	return ec.bearerToken != "" || ec.bearerTokenFile != ""
+}
+
+func (ec *envCredentials) tokenOptions() []ClientOption {
+	var opts []ClientOption
+	switch {
+	case ec.apiKey != "":
+		opts = append(opts, WithDgraphAPIKey(ec.apiKey))
+	case ec.apiKeyFile != "":
+		opts = append(opts, WithDgraphAPIKeyFile(ec.apiKeyFile))
+	}
+	switch {
+	case ec.bearerToken != "":
+		opts = append(opts, WithBearerToken(ec.bearerToken))
+	case ec.bearerTokenFile != "":
+		opts = append(opts, WithBearerTokenFile(ec.bearerTokenFile))
+	}
+	return opts
+}
+
+// lookupEnvSecret returns the value of the environment variable name, or the path
+// set in the variable name_FILE. Empty variables are treated as unset.
+func lookupEnvSecret(name string) (value, file string, err error) {
+	value, file = os.Getenv(name), os.Getenv(name+envFileSuffix)
+	if value != "" && file != "" {
+		return "", "", fmt.Errorf("only one of %s or %s can be set", name, name+envFileSuffix)
+	}
+	return value, file, nil
+}
+
+// readEnvSecret is like lookupEnvSecret, but reads the file if the variable with
+// the "_FILE" suffix is set.
+func readEnvSecret(name string) (string, error) {
+	value, file, err := lookupEnvSecret(name)
+	if err != nil || file == "" {
+		return value, err
+	}
+	value, err = readSecretFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", name+envFileSuffix, err)
+	}
+	return value, nil
+}
+
+// readSecretFile reads a secret from a file, removing trailing newlines, which are
+// commonly added by editors and when creating secrets using echo.
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimRight(string(data), "\r\n")
+	if value == "" {
+		return "", fmt.Errorf("file [%s] is empty", path)
+	}
+	return value, nil
+}
@@ -0,0 +1,238 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dgraph-io/dgo/v250"
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+func unsetDgraphEnv(t *testing.T) {
+	for _, name := range []string{dgo.EnvConnectionString, dgo.EnvUser, dgo.EnvPassword,
+		dgo.EnvAPIKey, dgo.EnvBearerToken} {
+
+		t.Setenv(name, "")
+		t.Setenv(name+"_FILE", "")
+	}
+}
+
+func writeSecret(t *testing.T, name, value string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(value), 0o600))
+	return path
+}
+
+func TestOpenFromEnvOffline(t *testing.T) {
+	var logins []*api.LoginRequest
+	srv := &fakeDgraphServer{login: func(_ context.Context, req *api.LoginRequest) (*api.Response, error) {
+		logins = append(logins, req)
+		jwt, err := proto.Marshal(&api.Jwt{AccessJwt: "access", RefreshJwt: "refresh"})
+		return &api.Response{Json: jwt}, err
+	}}
+	addr, _ := startFakeServer(t, "127.0.0.1:0", nil, srv)
+
+	unsetDgraphEnv(t)
+	t.Setenv(dgo.EnvConnectionString+"_FILE", writeSecret(t, "conn", "dgraph://groot@"+addr+"\n"))
+	t.Setenv(dgo.EnvPassword+"_FILE", writeSecret(t, "password", "s3cr3t \n"))
+	dg, err := dgo.OpenFromEnv(dgo.WithNamespace(2))
+	require.NoError(t, err)
+	dg.Close()
+
+	unsetDgraphEnv(t)
+	t.Setenv(dgo.EnvConnectionString, "dgraph://"+addr)
+	t.Setenv(dgo.EnvUser, "alice")
+	t.Setenv(dgo.EnvPassword, "password")
+	dg, err = dgo.OpenFromEnv()
+	require.NoError(t, err)
+	dg.Close()
+
+	unsetDgraphEnv(t)
+	dg, err = dgo.NewClient(addr, dgo.WithGrpcOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		dgo.WithACLCredsFile("bob", writeSecret(t, "bob", "bobs-password\r\n")))
+	require.NoError(t, err)
+	dg.Close()
+
+	require.Len(t, logins, 3)
+	// Only trailing newlines are removed from passwords.
+	require.Equal(t, "groot", logins[0].Userid)
+	require.Equal(t, "s3cr3t ", logins[0].Password)
+	require.Equal(t, uint64(2), logins[0].Namespace)
+	require.Equal(t, "alice", logins[1].Userid)
+	require.Equal(t, "password", logins[1].Password)
+	require.Equal(t, "bob", logins[2].Userid)
+	require.Equal(t, "bobs-password", logins[2].Password)
+}
+
+func TestWithEnvCredentialsOffline(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca.writeCert(t, caFile)
+
+	var authHeaders []string
+	srv := &fakeDgraphServer{query: func(ctx context.Context, req *api.Request) (*api.Response, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		authHeaders = append(authHeaders, md.Get("authorization")...)
+		return &api.Response{Json: []byte(`{}`)}, nil
+	}}
+	addr, _ := startFakeServer(t, "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{ca.issue(t, "alpha", x509.ExtKeyUsageServerAuth, "alpha.internal")},
+	}, srv)
+
+	unsetDgraphEnv(t)
+	tokenFile := writeSecret(t, "token", "token-1\n")
+	t.Setenv(dgo.EnvBearerToken+"_FILE", tokenFile)
+	dg, err := dgo.NewClient(addr, dgo.WithCACertFile(caFile), dgo.WithServerName("alpha.internal"),
+		dgo.WithEnvCredentials())
+	require.NoError(t, err)
+	defer dg.Close()
+
+	ctx := context.Background()
+	_, err = dg.NewReadOnlyTxn().Query(ctx, "{}")
+	require.NoError(t, err)
+	// The token file is read again once it changes.
+	require.NoError(t, os.WriteFile(tokenFile, []byte("token-2-rotated\n"), 0o600))
+	_, err = dg.NewReadOnlyTxn().Query(ctx, "{}")
+	require.NoError(t, err)
+
+	unsetDgraphEnv(t)
+	t.Setenv(dgo.EnvAPIKey, "api-key")
+	dgKey, err := dgo.NewClient(addr, dgo.WithCACertFile(caFile), dgo.WithServerName("alpha.internal"),
+		dgo.WithEnvCredentials())
+	require.NoError(t, err)
+	defer dgKey.Close()
+	_, err = dgKey.NewReadOnlyTxn().Query(ctx, "{}")
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"Bearer token-1", "Bearer token-2-rotated", "api-key"}, authHeaders)
+}
+
+func TestEnvOptions(t *testing.T) {
+	const secret = "hunter2"
+	missingFile := filepath.Join(t.TempDir(), "missing")
+	emptyFile := writeSecret(t, "empty", "\n")
+
+	// The cases with options create a client with them instead of using OpenFromEnv.
+	tests := []struct {
+		env  map[string]string
+		opts []dgo.ClientOption
+		err  string
+	}{
+		{
+			env: map[string]string{},
+			err: "neither DGRAPH_CONNECTION_STRING nor DGRAPH_CONNECTION_STRING_FILE is set",
+		},
+		{
+			env: map[string]string{
+				"DGRAPH_CONNECTION_STRING":      "dgraph://localhost:9080",
+				"DGRAPH_CONNECTION_STRING_FILE": missingFile,
+			},
+			err: "only one of DGRAPH_CONNECTION_STRING or DGRAPH_CONNECTION_STRING_FILE can be set",
+		},
+		{
+			env: map[string]string{"DGRAPH_CONNECTION_STRING_FILE": missingFile},
+			err: "failed to read DGRAPH_CONNECTION_STRING_FILE: open " + missingFile,
+		},
+		{
+			env: map[string]string{"DGRAPH_CONNECTION_STRING_FILE": emptyFile},
+			err: "failed to read DGRAPH_CONNECTION_STRING_FILE: file [" + emptyFile + "] is empty",
+		},
+		{
+			env: map[string]string{"DGRAPH_CONNECTION_STRING": "dgraph://groot:" + secret + "@localhost:9080:9080"},
+			err: "invalid connection string",
+		},
+		{
+			env: map[string]string{
+				"DGRAPH_CONNECTION_STRING": "dgraph://groot:" + secret + "@localhost:9080",
+				"DGRAPH_PASSWORD":          secret,
+			},
+			err: "password is set in both the connection string and DGRAPH_PASSWORD",
+		},
+		{
+			env: map[string]string{
+				"DGRAPH_CONNECTION_STRING": "dgraph://groot@localhost:9080",
+				"DGRAPH_USER":              "groot",
+				"DGRAPH_PASSWORD":          secret,
+			},
+			err: "username is set in both the connection string and DGRAPH_USER",
+		},
+		{
+			env: map[string]string{
+				"DGRAPH_CONNECTION_STRING": "dgraph://groot@localhost:9080",
+			},
+			err: "invalid connection string: both username and password must be provided",
+		},
+		{
+			env: map[string]string{
+				"DGRAPH_CONNECTION_STRING": "dgraph://localhost:9080",
+				"DGRAPH_PASSWORD":          secret,
+			},
+			err: "invalid connection string: both username and password must be provided",
+		},
+		{
+			env: map[string]string{
+				"DGRAPH_CONNECTION_STRING": "dgraph://localhost:9080?sslmode=verify-ca&apikey=" + secret,
+				"DGRAPH_API_KEY":           secret,
+			},
+			err: "API key is set in both the connection string and DGRAPH_API_KEY",
+		},
+		{
+			env: map[string]string{
+				"DGRAPH_CONNECTION_STRING": "dgraph://localhost:9080?sslmode=verify-ca&bearertoken=" + secret,
+				"DGRAPH_API_KEY_FILE":      missingFile,
+			},
+			err: "only one of API key or bearer token can be provided",
+		},
+		{
+			env: map[string]string{
+				"DGRAPH_CONNECTION_STRING": "dgraph://localhost:9080?sslmode=verify-ca",
+				"DGRAPH_BEARER_TOKEN_FILE": missingFile,
+			},
+			err: "failed to read token file: stat " + missingFile,
+		},
+		{
+			env:  map[string]string{dgo.EnvUser: "groot"},
+			opts: []dgo.ClientOption{dgo.WithEnvCredentials()},
+			err:  "both DGRAPH_USER and DGRAPH_PASSWORD must be set",
+		},
+		{
+			env:  map[string]string{dgo.EnvAPIKey: "key", dgo.EnvBearerToken: "token"},
+			opts: []dgo.ClientOption{dgo.WithEnvCredentials()},
+			err:  "only one of DGRAPH_API_KEY or DGRAPH_BEARER_TOKEN can be set",
+		},
+		{
+			opts: []dgo.ClientOption{dgo.WithACLCredsFile("groot", missingFile)},
+			err:  "failed to read password file",
+		},
+	}
+
+	for _, tc := range tests {
+		unsetDgraphEnv(t)
+		for name, value := range tc.env {
+			t.Setenv(name, value)
+		}
+		var err error
+		if tc.opts != nil {
+			_, err = dgo.NewClient("localhost:9080", tc.opts...)
+		} else {
+			_, err = dgo.OpenFromEnv()
+		}
+		require.ErrorContains(t, err, tc.err)
+		require.NotContains(t, err.Error(), secret)
+	}
+}
@@ -0,0 +1,370 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+const defaultExportBatchSize = 1000
+
+// ExportFormat is the format of the data written by Export.
+type ExportFormat string
+
+const (
+	// ExportRDF writes the data as RDF N-Quads. This is the default.
+	ExportRDF ExportFormat = "rdf"
+	// ExportJSON writes the data as a JSON array of nodes that can be used in
+	// JSON mutations.
+	ExportJSON ExportFormat = "json"
+)
+
+type exportOptions struct {
+	format    ExportFormat
+	gzip      bool
+	batchSize int
+	schema    io.Writer
+}
+
+// ExportOption is a function that modifies the export options.
+type ExportOption func(*exportOptions) error
+
+// WithExportFormat sets the format of the exported data.
+func WithExportFormat(format ExportFormat) ExportOption {
+	return func(o *exportOptions) error {
+		switch format {
+		case ExportRDF, ExportJSON:
+			o.format = format
+			return nil
+		default:
+			return fmt.Errorf("invalid export format: %s", format)
+		}
+	}
+}
+
+// WithExportGzip compresses the exported data using gzip.
+func WithExportGzip() ExportOption {
+	return func(o *exportOptions) error {
+		o.gzip = true
+		return nil
+	}
+}
+
+// WithExportBatchSize sets the number of nodes read by each query, 1000 by default.
+func WithExportBatchSize(size int) ExportOption {
+	return func(o *exportOptions) error {
+		if size <= 0 {
+			return fmt.Errorf("invalid batch size: %d", size)
+		}
+		o.batchSize = size
+		return nil
+	}
+}
+
+// WithExportSchema writes the schema, in the schema language, to the given writer.
+func WithExportSchema(w io.Writer) ExportOption {
+	return func(o *exportOptions) error {
+		o.schema = w
+		return nil
+	}
+}
+
+// Export writes the data of the namespace that the client is logged into to w.
+// The predicates are read from the schema, and the nodes of every predicate are
+// read with paginated queries in a single read-only transaction, so the export is
+// a consistent snapshot of the data. Facets and language tags are included.
+//
+// The data is written predicate by predicate, so a node is written once for every
+// predicate it has. Predicates managed by Dgraph, except for dgraph.type, aren't
+// exported, and neither are the values of password predicates. Unlike the export
+// run by Dgraph, it doesn't need access to the file system of the Alpha nodes.
+//
+// JSON responses don't distinguish datetime facets from string facets, so a string
+// facet holding an RFC3339 timestamp is exported as a datetime in the RDF format.
+func (d *Dgraph) Export(ctx context.Context, w io.Writer, opts ...ExportOption) error {
+	eo := &exportOptions{format: ExportRDF, batchSize: defaultExportBatchSize}
+	for _, opt := range opts {
+		if err := opt(eo); err != nil {
+			return err
+		}
+	}
+
+	preds, schema, err := readSchema(ctx, d)
+	if err != nil {
+		return err
+	}
+	if eo.schema != nil {
+		if _, err := io.WriteString(eo.schema, schema+"\n"); err != nil {
+			return fmt.Errorf("error writing schema: %w", err)
+		}
+	}
+
+	var gz *gzip.Writer
+	if eo.gzip {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+	bw := bufio.NewWriter(w)
+
+	ew := &exportWriter{w: bw, format: eo.format}
+	if err := ew.begin(); err != nil {
+		return err
+	}
+	txn := d.NewReadOnlyTxn()
+	preds = append(preds, &schemaPredicate{Predicate: "dgraph.type", Type: "string", List: true})
+	for _, pred := range preds {
+		if pred.Type == "password" {
+			continue
+		}
+		for resp, err := range txn.QueryPages(ctx, predicateQuery(pred), nil, "q", eo.batchSize) {
+			if err != nil {
+				return fmt.Errorf("error exporting predicate %s: %w", pred.Predicate, err)
+			}
+			if err := ew.writePage(pred, resp); err != nil {
+				return fmt.Errorf("error exporting predicate %s: %w", pred.Predicate, err)
+			}
+		}
+	}
+	if err := ew.end(); err != nil {
+		return err
+	}
+
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if gz != nil {
+		return gz.Close()
+	}
+	return nil
+}
+
+type exportWriter struct {
+	w      *bufio.Writer
+	format ExportFormat
+	nodes  int
+}
+
+func (ew *exportWriter) begin() error {
+	if ew.format == ExportJSON {
+		_, err := ew.w.WriteString("[")
+		return err
+	}
+	return nil
+}
+
+func (ew *exportWriter) end() error {
+	if ew.format == ExportJSON {
+		_, err := ew.w.WriteString("\n]\n")
+		return err
+	}
+	return nil
+}
+
+func (ew *exportWriter) writePage(pred *schemaPredicate, resp *api.Response) error {
+	for node, err := range IterBlock[map[string]json.RawMessage](resp, "q") {
+		if err != nil {
+			return err
+		}
+		if ew.format == ExportJSON {
+			err = ew.writeJSON(pred, node)
+		} else {
+			err = ew.writeRDF(pred, node)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ew *exportWriter) writeJSON(pred *schemaPredicate, node map[string]json.RawMessage) error {
+	if pred.Type == "float32vector" {
+		// Vectors are returned as arrays, but must be set as strings.
+		for key, value := range node {
+			if key != "uid" && bytes.HasPrefix(value, []byte("[")) {
+				quoted, err := json.Marshal(string(value))
+				if err != nil {
+					return err
+				}
+				node[key] = quoted
+			}
+		}
+	}
+	data, err := json.Marshal(node)
+	if err != nil {
+		return err
+	}
+	if ew.nodes > 0 {
+		if _, err := ew.w.WriteString(","); err != nil {
+			return err
+		}
+	}
+	ew.nodes++
+	if _, err := ew.w.WriteString("\n  "); err != nil {
+		return err
+	}
+	_, err = ew.w.Write(data)
+	return err
+}
+
+func (ew *exportWriter) writeRDF(pred *schemaPredicate, node map[string]json.RawMessage) error {
+	var subject string
+	if err := json.Unmarshal(node["uid"], &subject); err != nil {
+		return errors.New("node without uid in export")
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(node)) {
+		name, lang, _ := strings.Cut(key, "@")
+		if name != pred.Predicate || strings.Contains(lang, "|") {
+			// Skip the uid and facets.
+			continue
+		}
+
+		values, isList := []json.RawMessage{node[key]}, false
+		if pred.Type != "float32vector" {
+			values, isList = splitList(node[key])
+		}
+		for i, value := range values {
+			var object string
+			var err error
+			var valueFacets map[string]json.RawMessage
+			if pred.Type == "uid" {
+				var target map[string]json.RawMessage
+				if err := json.Unmarshal(value, &target); err != nil {
+					return err
+				}
+				var uid string
+				if err := json.Unmarshal(target["uid"], &uid); err != nil {
+					return err
+				}
+				object = "<" + uid + ">"
+				valueFacets = facetsOf(target, pred.Predicate, -1)
+			} else {
+				object, err = rdfLiteral(pred.Type, lang, value)
+				if err != nil {
+					return err
+				}
+				index := -1
+				if isList {
+					index = i
+				}
+				// Facets of values with a language tag are keyed by the tag too.
+				valueFacets = facetsOf(node, key, index)
+			}
+			line := fmt.Sprintf("<%s> <%s> %s%s .\n", subject, pred.Predicate, object, rdfFacets(valueFacets))
+			if _, err := ew.w.WriteString(line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// splitList returns the elements of a JSON array, or the value itself if it is
+// not an array.
+func splitList(value json.RawMessage) ([]json.RawMessage, bool) {
+	var values []json.RawMessage
+	if bytes.HasPrefix(value, []byte("[")) && json.Unmarshal(value, &values) == nil {
+		return values, true
+	}
+	return []json.RawMessage{value}, false
+}
+
+// facetsOf returns the facets of the predicate in the given object. Facets of the
+// values of a list predicate are keyed by the index of the value, in which case
+// index selects the value.
+func facetsOf(obj map[string]json.RawMessage, pred string, index int) map[string]json.RawMessage {
+	facets := make(map[string]json.RawMessage)
+	prefix := pred + "|"
+	for key, value := range obj {
+		name, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		if index < 0 {
+			facets[name] = value
+			continue
+		}
+		var byIndex map[string]json.RawMessage
+		if err := json.Unmarshal(value, &byIndex); err == nil {
+			if v, ok := byIndex[fmt.Sprint(index)]; ok {
+				facets[name] = v
+			}
+		}
+	}
+	return facets
+}
+
+// rdfFacets formats facets for an N-Quad, e.g. ` (since=2020, close=true)`.
+func rdfFacets(facets map[string]json.RawMessage) string {
+	if len(facets) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(facets))
+	for _, name := range slices.Sorted(maps.Keys(facets)) {
+		parts = append(parts, name+"="+rdfFacetValue(facets[name]))
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// rdfFacetValue formats the value of a facet returned by a JSON query. Numbers and
+// booleans are returned as such, but datetimes are returned as strings, and must
+// not be quoted to keep their type.
+func rdfFacetValue(value json.RawMessage) string {
+	var s string
+	if json.Unmarshal(value, &s) != nil {
+		return string(value)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return s
+	}
+	return rdfString(s)
+}
+
+// rdfTypes maps the types of the schema to the types of RDF literals.
+var rdfTypes = map[string]string{
+	"int":           "xs:int",
+	"float":         "xs:float",
+	"bool":          "xs:boolean",
+	"datetime":      "xs:dateTime",
+	"geo":           "geo:geojson",
+	"float32vector": "float32vector",
+}
+
+// rdfLiteral formats a value returned by a JSON query as an RDF literal. Strings
+// are decoded and quoted with the escapes of N-Quads, while numbers, booleans, geo
+// objects and vectors are quoted as they are.
+func rdfLiteral(typ, lang string, value json.RawMessage) (string, error) {
+	text := string(value)
+	if bytes.HasPrefix(value, []byte(`"`)) {
+		if err := json.Unmarshal(value, &text); err != nil {
+			return "", err
+		}
+	}
+	quoted := rdfString(text)
+
+	switch {
+	case lang != "":
+		return quoted + "@" + lang, nil
+	case rdfTypes[typ] != "":
+		return quoted + "^^<" + rdfTypes[typ] + ">", nil
+	default:
+		return quoted, nil
+	}
+}
@@ -0,0 +1,168 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgo/v250"
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+func newExportFake() *fakeDgraphClient {
+	pages := map[string]string{
+		"name": `[{"uid": "0x1", "name": "Alice \"A\"\n\u003cA\u003e", "name|verified": true,
+			"name|since": "2020-01-02T03:04:05Z", "name|note": "2020-01-02",
+			"name@fr": "Alicé", "name@fr|verified": false, "name@fr|by": "Bob"}]`,
+		"age":         `[{"uid": "0x1", "age": 30}, {"uid": "0x2", "age": 25}]`,
+		"tags":        `[{"uid": "0x1", "tags": ["a", "b"], "tags|weight": {"1": 0.5}}]`,
+		"friend":      `[{"uid": "0x1", "friend": [{"uid": "0x2", "friend|since": 2020}]}]`,
+		"loc":         `[{"uid": "0x2", "loc": {"type": "Point", "coordinates": [1, 2]}}]`,
+		"dgraph.type": `[{"uid": "0x1", "dgraph.type": ["Person"]}]`,
+	}
+	return &fakeDgraphClient{
+		query: func(_ context.Context, req *api.Request) (*api.Response, error) {
+			if req.Query == "schema {}" {
+				return &api.Response{Json: []byte(`{
+					"schema": [
+						{"predicate": "name", "type": "string", "index": true, "tokenizer": ["exact"], "lang": true},
+						{"predicate": "age", "type": "int"},
+						{"predicate": "tags", "type": "string", "list": true},
+						{"predicate": "friend", "type": "uid", "list": true},
+						{"predicate": "loc", "type": "geo"},
+						{"predicate": "secret", "type": "password"}
+					],
+					"types": [{"name": "Person", "fields": [{"name": "name"}, {"name": "age"}]}]
+				}`)}, nil
+			}
+			if strings.Contains(req.Query, "has(<secret>)") {
+				return nil, io.ErrUnexpectedEOF
+			}
+			page := "[]"
+			if req.Vars["$after"] == "0x0" {
+				for pred, nodes := range pages {
+					if strings.Contains(req.Query, "has(<"+pred+">)") {
+						page = nodes
+					}
+				}
+			}
+			return &api.Response{Json: []byte(`{"q": ` + page + `}`), Txn: &api.TxnContext{StartTs: 7}}, nil
+		},
+	}
+}
+
+func TestExportOffline(t *testing.T) {
+	dg := dgo.NewDgraphClient(newExportFake())
+	ctx := context.Background()
+
+	var rdf, schema bytes.Buffer
+	require.NoError(t, dg.Export(ctx, &rdf, dgo.WithExportSchema(&schema), dgo.WithExportBatchSize(10)))
+	// String facets stay quoted unless they hold an RFC3339 timestamp.
+	require.Equal(t, `<0x1> <name> "Alice \"A\"\n<A>" (note="2020-01-02", since=2020-01-02T03:04:05Z, verified=true) .
+<0x1> <name> "Alicé"@fr (by="Bob", verified=false) .
+<0x1> <age> "30"^^<xs:int> .
+<0x2> <age> "25"^^<xs:int> .
+<0x1> <tags> "a" .
+<0x1> <tags> "b" (weight=0.5) .
+<0x1> <friend> <0x2> (since=2020) .
+<0x2> <loc> "{\"type\": \"Point\", \"coordinates\": [1, 2]}"^^<geo:geojson> .
+<0x1> <dgraph.type> "Person" .
+`, rdf.String())
+	require.Equal(t, `<name>: string @index(exact) @lang .
+<age>: int .
+<tags>: [string] .
+<friend>: [uid] .
+<loc>: geo .
+<secret>: password .
+type <Person> {
+	<name>
+	<age>
+}
+`, schema.String())
+
+	var out bytes.Buffer
+	require.NoError(t, dg.Export(ctx, &out, dgo.WithExportFormat(dgo.ExportJSON), dgo.WithExportGzip()))
+	gz, err := gzip.NewReader(&out)
+	require.NoError(t, err)
+	data, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"uid": "0x1", "name": "Alice \"A\"\n<A>", "name|verified": true, "name|since": "2020-01-02T03:04:05Z",
+			"name|note": "2020-01-02",
+			"name@fr": "Alicé", "name@fr|verified": false, "name@fr|by": "Bob"},
+		{"uid": "0x1", "age": 30},
+		{"uid": "0x2", "age": 25},
+		{"uid": "0x1", "tags": ["a", "b"], "tags|weight": {"1": 0.5}},
+		{"uid": "0x1", "friend": [{"uid": "0x2", "friend|since": 2020}]},
+		{"uid": "0x2", "loc": {"type": "Point", "coordinates": [1, 2]}},
+		{"uid": "0x1", "dgraph.type": ["Person"]}
+	]`, string(data))
+}
+
+func TestExportOptions(t *testing.T) {
+	dg := dgo.NewDgraphClient(newExportFake())
+
+	tests := []struct {
+		opt dgo.ExportOption
+		err string
+	}{
+		{opt: dgo.WithExportFormat("csv"), err: "invalid export format: csv"},
+		{opt: dgo.WithExportBatchSize(0), err: "invalid batch size: 0"},
+	}
+
+	for _, tc := range tests {
+		err := dg.Export(context.Background(), io.Discard, tc.opt)
+		require.ErrorContains(t, err, tc.err)
+	}
+}
+
+func TestExport(t *testing.T) {
+	dg, cancel := getDgraphClient()
+	defer cancel()
+
+	ctx := context.Background()
+	require.NoError(t, dg.DropAll(ctx))
+	require.NoError(t, dg.SetSchema(ctx, `
+		name: string @index(exact) @lang .
+		friend: [uid] .
+	`))
+	resp, err := dg.NewTxn().Mutate(ctx, &api.Mutation{
+		SetNquads: []byte(`
+			_:a <name> "Alice" .
+			_:a <name> "Alicé"@fr (by="Bob") .
+			_:b <name> "Bob" .
+			_:a <friend> _:b (since=2020-01-02T03:04:05Z) .
+		`),
+		CommitNow: true,
+	})
+	require.NoError(t, err)
+	a, b := resp.Uids["a"], resp.Uids["b"]
+
+	var rdf bytes.Buffer
+	require.NoError(t, dg.Export(ctx, &rdf, dgo.WithExportBatchSize(1)))
+	require.ElementsMatch(t, []string{
+		"<" + a + `> <name> "Alice" .`,
+		"<" + a + `> <name> "Alicé"@fr (by="Bob") .`,
+		"<" + b + `> <name> "Bob" .`,
+		"<" + a + "> <friend> <" + b + "> (since=2020-01-02T03:04:05Z) .",
+	}, strings.Split(strings.TrimSpace(rdf.String()), "\n"))
+
+	// The export can be loaded again.
+	require.NoError(t, dg.DropData(ctx))
+	_, err = dg.NewTxn().Mutate(ctx, &api.Mutation{SetNquads: rdf.Bytes(), CommitNow: true})
+	require.NoError(t, err)
+	q, err := dg.NewReadOnlyTxn().Query(ctx, `{ q(func: eq(name, "Alice")) { name@fr @facets friend @facets { name } } }`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"q": [{"name@fr": "Alicé", "name@fr|by": "Bob",
+		"friend": [{"name": "Bob", "friend|since": "2020-01-02T03:04:05Z"}]}]}`, string(q.Json))
+}
@@ -0,0 +1,228 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package graphql is a client for the GraphQL endpoints of a Dgraph cluster, such
+// as /graphql and /admin. It can authenticate requests using the credentials of a
+// dgo.Dgraph client, so the same credentials are used for the gRPC and HTTP APIs.
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Request is a GraphQL request.
+type Request struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	OperationName string         `json:"operationName,omitempty"`
+}
+
+// Response is a GraphQL response.
+type Response struct {
+	Data       json.RawMessage `json:"data,omitempty"`
+	Errors     Errors          `json:"errors,omitempty"`
+	Extensions map[string]any  `json:"extensions,omitempty"`
+}
+
+// Location is a location in a GraphQL document.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error is an error in a GraphQL response.
+type Error struct {
+	Message   string     `json:"message"`
+	Locations []Location `json:"locations,omitempty"`
+	// Path is the path of the response field that the error belongs to, made up
+	// of field names and list indexes.
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Message
+}
+
+// Errors is the list of errors in a GraphQL response.
+type Errors []*Error
+
+func (errs Errors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Auth provides the headers that authenticate requests, such as a dgo.Dgraph
+// client. If it also has a Relogin method, as dgo.Dgraph does, the method is
+// called when a request fails because the access JWT has expired, after which
+// the request is retried.
+type Auth interface {
+	AuthHeaders(ctx context.Context) (http.Header, error)
+}
+
+type relogger interface {
+	Relogin(ctx context.Context) error
+}
+
+type clientOptions struct {
+	httpClient *http.Client
+	header     http.Header
+	auth       Auth
+}
+
+// ClientOption is a function that modifies the client options.
+type ClientOption func(*clientOptions) error
+
+// WithHTTPClient sets the HTTP client used for requests, http.DefaultClient by default.
+func WithHTTPClient(c *http.Client) ClientOption {
+	return func(o *clientOptions) error {
+		o.httpClient = c
+		return nil
+	}
+}
+
+// WithHeader adds a header to every request.
+func WithHeader(key, value string) ClientOption {
+	return func(o *clientOptions) error {
+		o.header.Add(key, value)
+		return nil
+	}
+}
+
+// WithAuth authenticates requests using the headers provided by auth, e.g. a
+// dgo.Dgraph client logged in using ACL credentials or created with an API key.
+func WithAuth(auth Auth) ClientOption {
+	return func(o *clientOptions) error {
+		o.auth = auth
+		return nil
+	}
+}
+
+// Client runs GraphQL requests against a GraphQL endpoint. It is safe for
+// concurrent use.
+type Client struct {
+	url  string
+	opts clientOptions
+}
+
+// NewClient creates a client for the GraphQL endpoint at the given URL, e.g.
+// http://localhost:8080/graphql.
+func NewClient(url string, opts ...ClientOption) (*Client, error) {
+	if url == "" {
+		return nil, errors.New("GraphQL endpoint URL cannot be empty")
+	}
+	c := &Client{url: url, opts: clientOptions{httpClient: http.DefaultClient, header: make(http.Header)}}
+	for _, opt := range opts {
+		if err := opt(&c.opts); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Do runs the request and returns the response, including any GraphQL errors in it.
+// An error is returned only if the request fails or the response is not a GraphQL
+// response.
+func (c *Client) Do(ctx context.Context, req *Request) (*Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal GraphQL request: %w", err)
+	}
+
+	resp, err := c.do(ctx, body)
+	if err != nil || !isJwtExpired(resp.Errors) {
+		return resp, err
+	}
+	r, ok := c.opts.auth.(relogger)
+	if !ok {
+		return resp, nil
+	}
+	if err := r.Relogin(ctx); err != nil {
+		return nil, fmt.Errorf("unable to refresh access JWT: %w", err)
+	}
+	return c.do(ctx, body)
+}
+
+func (c *Client) do(ctx context.Context, body []byte) (*Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create GraphQL request: %w", err)
+	}
+	for key, values := range c.opts.header {
+		httpReq.Header[key] = values
+	}
+	if c.opts.auth != nil {
+		h, err := c.opts.auth.AuthHeaders(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range h {
+			httpReq.Header[key] = values
+		}
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := c.opts.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("GraphQL request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read GraphQL response: %w", err)
+	}
+	var resp Response
+	if err := json.Unmarshal(data, &resp); err != nil || (resp.Data == nil && resp.Errors == nil) {
+		if httpResp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("GraphQL request failed with status %d: %s",
+				httpResp.StatusCode, bytes.TrimSpace(data))
+		}
+		return nil, fmt.Errorf("invalid GraphQL response: %s", bytes.TrimSpace(data))
+	}
+	return &resp, nil
+}
+
+// Execute runs the query or mutation with the given variables and decodes the
+// data of the response into out, if not nil. If the response has errors, they
+// are returned as Errors, after decoding any data returned along with them.
+func (c *Client) Execute(ctx context.Context, query string, vars map[string]any, out any) error {
+	resp, err := c.Do(ctx, &Request{Query: query, Variables: vars})
+	if err != nil {
+		return err
+	}
+	if out != nil && len(resp.Data) > 0 && !bytes.Equal(resp.Data, []byte("null")) {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			return fmt.Errorf("unable to decode GraphQL data: %w", err)
+		}
+	}
+	if len(resp.Errors) > 0 {
+		return resp.Errors
+	}
+	return nil
+}
+
+// isJwtExpired returns true if the errors indicate that the access JWT has expired.
+func isJwtExpired(errs Errors) bool {
+	for _, err := range errs {
+		if strings.Contains(err.Message, "Token is expired") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgo/v250/graphql"
+)
+
+type fakeAuth struct {
+	token    string
+	relogins int
+}
+
+func (a *fakeAuth) AuthHeaders(context.Context) (http.Header, error) {
+	return http.Header{"X-Dgraph-Accesstoken": {a.token}}, nil
+}
+
+func (a *fakeAuth) Relogin(context.Context) error {
+	a.relogins++
+	a.token = "fresh"
+	return nil
+}
+
+func TestExecute(t *testing.T) {
+	var requests []*graphql.Request
+	var headers []http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, &req)
+		headers = append(headers, r.Header)
+
+		switch {
+		case r.Header.Get("X-Dgraph-AccessToken") == "expired":
+			_, _ = w.Write([]byte(`{"errors": [{"message": "Token is expired"}]}`))
+		case req.Variables["name"] == "Bob":
+			_, _ = w.Write([]byte(`{
+				"data": {"getUser": null},
+				"errors": [{
+					"message": "user not found",
+					"locations": [{"line": 1, "column": 2}],
+					"path": ["getUser", 0],
+					"extensions": {"code": "NOT_FOUND"}
+				}]
+			}`))
+		default:
+			_, _ = w.Write([]byte(`{"data": {"getUser": {"name": "Alice", "age": 30}}}`))
+		}
+	}))
+	defer srv.Close()
+
+	auth := &fakeAuth{token: "expired"}
+	c, err := graphql.NewClient(srv.URL, graphql.WithAuth(auth), graphql.WithHeader("X-Custom", "1"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	var out struct {
+		GetUser *struct {
+			Name string `json:"name"`
+			Age  int    `json:"age"`
+		} `json:"getUser"`
+	}
+	q := `query($name: String!) { getUser(name: $name) { name age } }`
+	require.NoError(t, c.Execute(ctx, q, map[string]any{"name": "Alice"}, &out))
+	require.Equal(t, "Alice", out.GetUser.Name)
+	require.Equal(t, 30, out.GetUser.Age)
+
+	// The expired token was refreshed and the request retried.
+	require.Equal(t, 1, auth.relogins)
+	require.Len(t, requests, 2)
+	require.Equal(t, q, requests[1].Query)
+	require.Equal(t, "fresh", headers[1].Get("X-Dgraph-AccessToken"))
+	require.Equal(t, "1", headers[1].Get("X-Custom"))
+	require.Equal(t, "application/json", headers[1].Get("Content-Type"))
+
+	err = c.Execute(ctx, q, map[string]any{"name": "Bob"}, &out)
+	var errs graphql.Errors
+	require.True(t, errors.As(err, &errs))
+	require.Equal(t, graphql.Errors{{
+		Message:    "user not found",
+		Locations:  []graphql.Location{{Line: 1, Column: 2}},
+		Path:       []any{"getUser", float64(0)},
+		Extensions: map[string]any{"code": "NOT_FOUND"},
+	}}, errs)
+	require.EqualError(t, err, "user not found")
+	require.Nil(t, out.GetUser)
+}
+
+func TestExecuteErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	_, err := graphql.NewClient("")
+	require.ErrorContains(t, err, "GraphQL endpoint URL cannot be empty")
+
+	c, err := graphql.NewClient(srv.URL)
+	require.NoError(t, err)
+	err = c.Execute(context.Background(), `{ q }`, nil, nil)
+	require.EqualError(t, err, "GraphQL request failed with status 502: bad gateway")
+}
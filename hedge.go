@@ -0,0 +1,142 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+const (
+	// hedgeSamples is the number of recent query latencies used to compute the
+	// hedging delay.
+	hedgeSamples = 256
+	// hedgeMinSamples is the number of latencies needed before the percentile is
+	// used, the minimum delay being used until then.
+	hedgeMinSamples = 20
+)
+
+// WithHedgedReads enables hedging the queries of read-only transactions on a client
+// with more than one endpoint. If the endpoint of the transaction hasn't answered a
+// query after the given percentile of the latencies of the recent queries, or after
+// minDelay if that is longer, the query is also sent to another endpoint. The first
+// successful response is returned and the other request is cancelled.
+//
+// For example, WithHedgedReads(95, 10*time.Millisecond) hedges about 5% of the
+// queries once the latencies are known. Hedging adds load to the cluster, so the
+// percentile should be high.
+func WithHedgedReads(percentile float64, minDelay time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if percentile <= 0 || percentile >= 100 {
+			return fmt.Errorf("invalid hedging percentile: %v", percentile)
+		}
+		if minDelay <= 0 {
+			return fmt.Errorf("invalid hedging delay: %v", minDelay)
+		}
+		o.hedger = &hedger{percentile: percentile, minDelay: minDelay}
+		return nil
+	}
+}
+
+// hedger keeps track of the latencies of queries to compute the hedging delay.
+type hedger struct {
+	percentile float64
+	minDelay   time.Duration
+
+	mu      sync.Mutex
+	samples [hedgeSamples]time.Duration
+	n       int // number of samples recorded so far
+}
+
+// record records the latency of a successful query.
+func (h *hedger) record(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.samples[h.n%hedgeSamples] = d
+	h.n++
+}
+
+// delay returns how long to wait for a response before hedging a query.
+func (h *hedger) delay() time.Duration {
+	h.mu.Lock()
+	n := min(h.n, hedgeSamples)
+	if n < hedgeMinSamples {
+		h.mu.Unlock()
+		return h.minDelay
+	}
+	samples := slices.Clone(h.samples[:n])
+	h.mu.Unlock()
+
+	slices.Sort(samples)
+	d := samples[int(float64(n-1)*h.percentile/100)]
+	return max(d, h.minDelay)
+}
+
+// hedgedQuery sends a query of a read-only transaction to its endpoint and, if
+// that takes longer than the hedging delay, to another endpoint as well.
+func (txn *Txn) hedgedQuery(ctx context.Context, req *api.Request, hdrs *metadata.MD) (
+	*api.Response, error) {
+
+	h := txn.dg.hedger
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		resp *api.Response
+		hdrs metadata.MD
+		err  error
+	}
+	results := make(chan result, 2)
+	send := func(dc api.DgraphClient) {
+		start := time.Now()
+		var md metadata.MD
+		resp, err := dc.Query(ctx, req, grpc.Header(&md))
+		if err == nil {
+			h.record(time.Since(start))
+		}
+		results <- result{resp: resp, hdrs: md, err: err}
+	}
+
+	go send(txn.dc)
+	timer := time.NewTimer(h.delay())
+	defer timer.Stop()
+
+	pending, hedged := 1, false
+	var firstErr error
+	for {
+		select {
+		case <-timer.C:
+			n := len(txn.dg.dc)
+			//nolint:gosec
+			i := (txn.index + 1 + rand.Intn(n-1)) % n
+			hedged = true
+			pending++
+			go send(txn.dg.dc[i])
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				*hdrs = r.hdrs
+				return r.resp, nil
+			}
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			// An error before hedging, such as an expired JWT, is returned as is.
+			if !hedged || pending == 0 {
+				return nil, firstErr
+			}
+		}
+	}
+}
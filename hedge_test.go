@@ -0,0 +1,81 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/dgraph-io/dgo/v250"
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+func TestHedgedReadsOffline(t *testing.T) {
+	slow := &fakeDgraphServer{
+		query: func(ctx context.Context, req *api.Request) (*api.Response, error) {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(300 * time.Millisecond):
+				return &api.Response{Json: []byte(`"slow"`)}, nil
+			}
+		},
+	}
+	fast := &fakeDgraphServer{
+		query: func(ctx context.Context, req *api.Request) (*api.Response, error) {
+			return &api.Response{Json: []byte(`"fast"`)}, nil
+		},
+	}
+	slowAddr, _ := startFakeServer(t, "127.0.0.1:0", nil, slow)
+	fastAddr, _ := startFakeServer(t, "127.0.0.1:0", nil, fast)
+
+	dg, err := dgo.NewRoundRobinClient([]string{slowAddr, fastAddr},
+		dgo.WithGrpcOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		dgo.WithLoadBalancingPolicy(dgo.LoadBalanceRoundRobin),
+		dgo.WithHedgedReads(95, 50*time.Millisecond))
+	require.NoError(t, err)
+	defer dg.Close()
+
+	ctx := context.Background()
+	query := func(txn *dgo.Txn) string {
+		resp, err := txn.Query(ctx, `{ q(func: uid(1)) { uid } }`)
+		require.NoError(t, err)
+		return string(resp.Json)
+	}
+
+	// The query sent to the slow endpoint is hedged to the fast one.
+	start := time.Now()
+	require.Equal(t, `"fast"`, query(dg.NewReadOnlyTxn()))
+	require.Less(t, time.Since(start), 250*time.Millisecond)
+	require.Equal(t, `"fast"`, query(dg.NewReadOnlyTxn().BestEffort()))
+	require.EqualValues(t, 1, slow.queries.Load())
+	require.EqualValues(t, 2, fast.queries.Load())
+
+	// Queries of transactions that are not read-only are never hedged.
+	require.Equal(t, `"slow"`, query(dg.NewTxn()))
+	require.EqualValues(t, 2, slow.queries.Load())
+	require.EqualValues(t, 2, fast.queries.Load())
+}
+
+func TestHedgedReadsOptions(t *testing.T) {
+	tests := []struct {
+		opt dgo.ClientOption
+		err string
+	}{
+		{opt: dgo.WithHedgedReads(100, time.Millisecond), err: "invalid hedging percentile: 100"},
+		{opt: dgo.WithHedgedReads(95, 0), err: "invalid hedging delay: 0s"},
+	}
+
+	for _, tc := range tests {
+		_, err := dgo.NewClient("127.0.0.1:9180", tc.opt)
+		require.EqualError(t, err, tc.err)
+	}
+}
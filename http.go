@@ -0,0 +1,701 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+const (
+	contentTypeJSON = "application/json"
+	contentTypeRDF  = "application/rdf"
+)
+
+// WithHTTPTransport connects to the HTTP endpoints of the alphas, e.g.
+// localhost:8080, instead of the gRPC endpoints. Transactions and the helpers
+// built on them work the same way, but namespace management, RunDQL, AllocateIDs
+// and RDF responses are not available over HTTP. The gRPC specific options, such
+// as WithKeepalive and WithGrpcOption, are ignored.
+func WithHTTPTransport() ClientOption {
+	return func(o *clientOptions) error {
+		o.useHTTP = true
+		return nil
+	}
+}
+
+// httpClient implements api.DgraphClient using the HTTP API of an alpha. Errors
+// are returned as gRPC status errors, so that they are handled in the same way
+// as the errors of the gRPC client.
+type httpClient struct {
+	url     string
+	hc      *http.Client
+	auth    []httpAuth
+	timeout time.Duration
+}
+
+func newHTTPClient(endpoint string, co *clientOptions, r *certReloader) (*httpClient, error) {
+	base := endpoint
+	if !strings.Contains(endpoint, "://") {
+		scheme := "http"
+		if co.tlsConfig != nil {
+			scheme = "https"
+		}
+		base = scheme + "://" + endpoint
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTP endpoint [%s]: %w", endpoint, err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg := co.endpointTLSConfig(endpoint, r); cfg != nil {
+		transport.TLSClientConfig = cfg
+		if r != nil {
+			transport.DialTLSContext = dialTLSReloading(cfg, r)
+		}
+	}
+	return &httpClient{
+		url:     strings.TrimSuffix(u.String(), "/"),
+		hc:      &http.Client{Transport: transport},
+		auth:    co.httpAuth,
+		timeout: co.requestTimeout,
+	}, nil
+}
+
+// dialTLSReloading returns a function dialing TLS connections using the
+// certificates served by r, as newReloadingCreds does for gRPC connections.
+func dialTLSReloading(base *tls.Config, r *certReloader) func(context.Context, string, string) (net.Conn, error) {
+	var dialer net.Dialer
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		cfg := r.config(base)
+		if cfg.ServerName == "" {
+			cfg.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		tlsConn := tls.Client(conn, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+}
+
+// httpResponse is the body of a response of the HTTP API.
+type httpResponse struct {
+	Data       json.RawMessage `json:"data"`
+	Errors     []*httpError    `json:"errors"`
+	Extensions struct {
+		Txn *httpTxn `json:"txn"`
+	} `json:"extensions"`
+}
+
+type httpError struct {
+	Message    string `json:"message"`
+	Extensions struct {
+		Code string `json:"code"`
+	} `json:"extensions"`
+}
+
+// status converts the error into the status error the gRPC API returns for it.
+func (e *httpError) status() error {
+	code := codes.Unknown
+	switch {
+	case strings.Contains(e.Message, "Transaction has been aborted"):
+		code = codes.Aborted
+	case e.Extensions.Code == "ErrorUnauthorized":
+		code = codes.Unauthenticated
+	}
+	return status.Error(code, e.Message)
+}
+
+type httpTxn struct {
+	StartTs  uint64   `json:"start_ts"`
+	CommitTs uint64   `json:"commit_ts"`
+	Aborted  bool     `json:"aborted"`
+	Keys     []string `json:"keys"`
+	Preds    []string `json:"preds"`
+	Hash     string   `json:"hash"`
+}
+
+func (r *httpResponse) txnContext() *api.TxnContext {
+	t := r.Extensions.Txn
+	if t == nil {
+		return nil
+	}
+	return &api.TxnContext{
+		StartTs:  t.StartTs,
+		CommitTs: t.CommitTs,
+		Aborted:  t.Aborted,
+		Keys:     t.Keys,
+		Preds:    t.Preds,
+		Hash:     t.Hash,
+	}
+}
+
+func (c *httpClient) Login(ctx context.Context, in *api.LoginRequest,
+	opts ...grpc.CallOption) (*api.Response, error) {
+
+	body, err := json.Marshal(map[string]any{
+		"userid":        in.Userid,
+		"password":      in.Password,
+		"namespace":     in.Namespace,
+		"refresh_token": in.RefreshToken,
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	resp, err := c.call(ctx, "/login", nil, contentTypeJSON, body, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		AccessJWT  string `json:"accessJWT"`
+		RefreshJWT string `json:"refreshJWT"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, status.Errorf(codes.Internal, "invalid login response: %v", err)
+	}
+	// The gRPC API returns the JWTs as a serialized api.Jwt.
+	jwt, err := proto.Marshal(&api.Jwt{AccessJwt: data.AccessJWT, RefreshJwt: data.RefreshJWT})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &api.Response{Json: jwt}, nil
+}
+
+func (c *httpClient) Query(ctx context.Context, in *api.Request,
+	opts ...grpc.CallOption) (*api.Response, error) {
+
+	if in.RespFormat == api.Request_RDF {
+		return nil, status.Error(codes.Unimplemented, "RDF responses are not supported over HTTP")
+	}
+
+	params := url.Values{}
+	if in.StartTs != 0 {
+		params.Set("startTs", strconv.FormatUint(in.StartTs, 10))
+	}
+	if in.Hash != "" {
+		params.Set("hash", in.Hash)
+	}
+
+	if len(in.Mutations) == 0 {
+		if in.ReadOnly {
+			params.Set("ro", "true")
+		}
+		if in.BestEffort {
+			params.Set("be", "true")
+		}
+		body, err := json.Marshal(map[string]any{"query": in.Query, "variables": in.Vars})
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		resp, err := c.call(ctx, "/query", params, contentTypeJSON, body, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &api.Response{Json: resp.Data, Txn: resp.txnContext()}, nil
+	}
+
+	if in.CommitNow {
+		params.Set("commitNow", "true")
+	}
+	contentType, body, err := mutationBody(in)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.call(ctx, "/mutate", params, contentType, body, opts)
+	if err != nil {
+		return nil, err
+	}
+	var data struct {
+		Queries json.RawMessage   `json:"queries"`
+		Uids    map[string]string `json:"uids"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, status.Errorf(codes.Internal, "invalid mutation response: %v", err)
+	}
+	return &api.Response{Json: data.Queries, Uids: data.Uids, Txn: resp.txnContext()}, nil
+}
+
+func (c *httpClient) Alter(ctx context.Context, in *api.Operation,
+	opts ...grpc.CallOption) (*api.Payload, error) {
+
+	body, err := protojson.Marshal(in)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if _, err := c.call(ctx, "/alter", nil, contentTypeJSON, body, opts); err != nil {
+		return nil, err
+	}
+	return &api.Payload{}, nil
+}
+
+func (c *httpClient) CommitOrAbort(ctx context.Context, in *api.TxnContext,
+	opts ...grpc.CallOption) (*api.TxnContext, error) {
+
+	params := url.Values{"startTs": {strconv.FormatUint(in.StartTs, 10)}}
+	if in.Aborted {
+		params.Set("abort", "true")
+	}
+	if in.Hash != "" {
+		params.Set("hash", in.Hash)
+	}
+	body, err := json.Marshal(map[string][]string{"keys": in.Keys, "preds": in.Preds})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	resp, err := c.call(ctx, "/commit", params, contentTypeJSON, body, opts)
+	if err != nil {
+		return nil, err
+	}
+	if tc := resp.txnContext(); tc != nil {
+		return tc, nil
+	}
+	return &api.TxnContext{StartTs: in.StartTs, Aborted: in.Aborted}, nil
+}
+
+func (c *httpClient) CheckVersion(ctx context.Context, _ *api.Check,
+	opts ...grpc.CallOption) (*api.Version, error) {
+
+	data, err := c.send(ctx, http.MethodGet, "/health", nil, "", nil, opts)
+	if err != nil {
+		return nil, err
+	}
+	var health []struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(data, &health); err != nil || len(health) == 0 {
+		return nil, status.Errorf(codes.Internal, "invalid health response: %s", bytes.TrimSpace(data))
+	}
+	return &api.Version{Tag: health[0].Version}, nil
+}
+
+func (c *httpClient) RunDQL(context.Context, *api.RunDQLRequest,
+	...grpc.CallOption) (*api.Response, error) {
+	return nil, errHTTPUnimplemented("RunDQL")
+}
+
+func (c *httpClient) AllocateIDs(context.Context, *api.AllocateIDsRequest,
+	...grpc.CallOption) (*api.AllocateIDsResponse, error) {
+	return nil, errHTTPUnimplemented("AllocateIDs")
+}
+
+func (c *httpClient) UpdateExtSnapshotStreamingState(context.Context, *api.UpdateExtSnapshotStreamingStateRequest,
+	...grpc.CallOption) (*api.UpdateExtSnapshotStreamingStateResponse, error) {
+	return nil, errHTTPUnimplemented("UpdateExtSnapshotStreamingState")
+}
+
+func (c *httpClient) StreamExtSnapshot(context.Context,
+	...grpc.CallOption) (api.Dgraph_StreamExtSnapshotClient, error) {
+	return nil, errHTTPUnimplemented("StreamExtSnapshot")
+}
+
+func (c *httpClient) CreateNamespace(ctx context.Context, _ *api.CreateNamespaceRequest,
+	opts ...grpc.CallOption) (*api.CreateNamespaceResponse, error) {
+
+	const q = `mutation { addNamespace { namespaceId } }`
+	var data struct {
+		AddNamespace struct {
+			NamespaceID uint64 `json:"namespaceId"`
+		} `json:"addNamespace"`
+	}
+	if err := c.admin(ctx, q, nil, &data, opts); err != nil {
+		return nil, err
+	}
+	return &api.CreateNamespaceResponse{Namespace: data.AddNamespace.NamespaceID}, nil
+}
+
+func (c *httpClient) DropNamespace(ctx context.Context, in *api.DropNamespaceRequest,
+	opts ...grpc.CallOption) (*api.DropNamespaceResponse, error) {
+
+	const q = `mutation($id: Int!) { deleteNamespace(input: {namespaceId: $id}) { namespaceId } }`
+	if err := c.admin(ctx, q, map[string]any{"id": in.Namespace}, nil, opts); err != nil {
+		return nil, err
+	}
+	return &api.DropNamespaceResponse{}, nil
+}
+
+func (c *httpClient) ListNamespaces(context.Context, *api.ListNamespacesRequest,
+	...grpc.CallOption) (*api.ListNamespacesResponse, error) {
+	return nil, errHTTPUnimplemented("ListNamespaces")
+}
+
+func errHTTPUnimplemented(method string) error {
+	return status.Errorf(codes.Unimplemented, "%s is not supported over HTTP", method)
+}
+
+// admin runs a request of the GraphQL admin API, which manages the namespaces,
+// and decodes its data into out if not nil.
+func (c *httpClient) admin(ctx context.Context, q string, vars map[string]any, out any,
+	opts []grpc.CallOption) error {
+
+	body, err := json.Marshal(map[string]any{"query": q, "variables": vars})
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	resp, err := c.call(ctx, "/admin", nil, contentTypeJSON, body, opts)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Data, out); err != nil {
+		return status.Errorf(codes.Internal, "invalid admin response: %v", err)
+	}
+	return nil
+}
+
+// call sends a POST request and decodes the response, returning the errors in
+// it as a status error.
+func (c *httpClient) call(ctx context.Context, path string, params url.Values,
+	contentType string, body []byte, opts []grpc.CallOption) (*httpResponse, error) {
+
+	data, err := c.send(ctx, http.MethodPost, path, params, contentType, body, opts)
+	if err != nil {
+		return nil, err
+	}
+	var resp httpResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, status.Errorf(codes.Internal, "invalid response: %s", bytes.TrimSpace(data))
+	}
+	if len(resp.Errors) > 0 {
+		return nil, resp.Errors[0].status()
+	}
+	return &resp, nil
+}
+
+func (c *httpClient) send(ctx context.Context, method, path string, params url.Values,
+	contentType string, body []byte, opts []grpc.CallOption) ([]byte, error) {
+
+	if _, ok := ctx.Deadline(); !ok && c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	u := c.url + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if err := c.setHeaders(ctx, req.Header); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	setHeaderCallOptions(opts, resp.Header)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var errResp httpResponse
+		if json.Unmarshal(data, &errResp) == nil && len(errResp.Errors) > 0 {
+			return nil, errResp.Errors[0].status()
+		}
+		return nil, status.Errorf(httpStatusCode(resp.StatusCode), "request failed with status %d: %s",
+			resp.StatusCode, bytes.TrimSpace(data))
+	}
+	return data, nil
+}
+
+// setHeaders sets the outgoing gRPC metadata of ctx, which holds the access JWT
+// of the logged in user, and the credentials of the client as HTTP headers.
+func (c *httpClient) setHeaders(ctx context.Context, h http.Header) error {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	for key, values := range md {
+		if key == "accessjwt" {
+			key = accessTokenHeader
+		}
+		for _, v := range values {
+			h.Add(key, v)
+		}
+	}
+	for _, a := range c.auth {
+		if err := a.setHTTPAuth(ctx, h); err != nil {
+			return status.Error(codes.Unauthenticated, err.Error())
+		}
+	}
+	return nil
+}
+
+// setHeaderCallOptions sets the response headers for the grpc.Header call options.
+func setHeaderCallOptions(opts []grpc.CallOption, h http.Header) {
+	for _, opt := range opts {
+		if o, ok := opt.(grpc.HeaderCallOption); ok && o.HeaderAddr != nil {
+			md := make(metadata.MD, len(h))
+			for key, values := range h {
+				md.Append(key, values...)
+			}
+			*o.HeaderAddr = md
+		}
+	}
+}
+
+func httpStatusCode(code int) codes.Code {
+	switch code {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.Unimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
+
+// mutationBody returns the body of a request to the /mutate endpoint. JSON
+// mutations are sent as JSON, and N-Quad mutations in the RDF format, as an
+// upsert block if the request has a query.
+func mutationBody(req *api.Request) (string, []byte, error) {
+	if len(req.Vars) > 0 {
+		return "", nil, status.Error(codes.Unimplemented, "query variables in upserts are not supported over HTTP")
+	}
+
+	query := strings.TrimSpace(req.Query)
+	var hasJSON, hasRDF bool
+	for _, mu := range req.Mutations {
+		if mu.Cond != "" && query == "" {
+			return "", nil, status.Error(codes.InvalidArgument,
+				"conditional mutations require a query over HTTP")
+		}
+		hasJSON = hasJSON || len(mu.SetJson) > 0 || len(mu.DeleteJson) > 0
+		hasRDF = hasRDF || len(mu.SetNquads) > 0 || len(mu.DelNquads) > 0 || len(mu.Set) > 0 || len(mu.Del) > 0
+	}
+	if hasJSON && hasRDF {
+		return "", nil, status.Error(codes.InvalidArgument,
+			"JSON and N-Quad mutations cannot be combined in a request over HTTP")
+	}
+
+	if !hasRDF {
+		type jsonMutation struct {
+			Set    json.RawMessage `json:"set,omitempty"`
+			Delete json.RawMessage `json:"delete,omitempty"`
+			Cond   string          `json:"cond,omitempty"`
+		}
+		body := struct {
+			Query     string          `json:"query,omitempty"`
+			Mutations []*jsonMutation `json:"mutations"`
+		}{Query: req.Query}
+		for _, mu := range req.Mutations {
+			body.Mutations = append(body.Mutations, &jsonMutation{Set: mu.SetJson, Delete: mu.DeleteJson, Cond: mu.Cond})
+		}
+		data, err := json.Marshal(body)
+		if err != nil {
+			return "", nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return contentTypeJSON, data, nil
+	}
+
+	var b strings.Builder
+	if query == "" {
+		// Without a query, the mutations have no conditions, so they are merged
+		// into a single one.
+		var set, del []byte
+		for _, mu := range req.Mutations {
+			s, d, err := mutationNQuads(mu)
+			if err != nil {
+				return "", nil, err
+			}
+			set, del = append(set, s...), append(del, d...)
+		}
+		b.WriteString("{\n")
+		writeNQuadBlock(&b, "set", set)
+		writeNQuadBlock(&b, "delete", del)
+		b.WriteString("}\n")
+		return contentTypeRDF, []byte(b.String()), nil
+	}
+
+	if strings.HasPrefix(query, "{") {
+		query = "query " + query
+	}
+	b.WriteString("upsert {\n" + query + "\n")
+	for _, mu := range req.Mutations {
+		set, del, err := mutationNQuads(mu)
+		if err != nil {
+			return "", nil, err
+		}
+		b.WriteString("mutation " + mu.Cond + " {\n")
+		writeNQuadBlock(&b, "set", set)
+		writeNQuadBlock(&b, "delete", del)
+		b.WriteString("}\n")
+	}
+	b.WriteString("}\n")
+	return contentTypeRDF, []byte(b.String()), nil
+}
+
+func writeNQuadBlock(b *strings.Builder, name string, nquads []byte) {
+	if len(bytes.TrimSpace(nquads)) == 0 {
+		return
+	}
+	b.WriteString(name + " {\n")
+	b.Write(nquads)
+	b.WriteString("\n}\n")
+}
+
+// mutationNQuads returns the N-Quads to set and delete in the mutation, including
+// its api.NQuad values.
+func mutationNQuads(mu *api.Mutation) ([]byte, []byte, error) {
+	set, err := appendNQuads(mu.SetNquads, mu.Set)
+	if err != nil {
+		return nil, nil, err
+	}
+	del, err := appendNQuads(mu.DelNquads, mu.Del)
+	if err != nil {
+		return nil, nil, err
+	}
+	return set, del, nil
+}
+
+func appendNQuads(raw []byte, nquads []*api.NQuad) ([]byte, error) {
+	out := append([]byte(nil), raw...)
+	for _, nq := range nquads {
+		s, err := nquadString(nq)
+		if err != nil {
+			return nil, err
+		}
+		if len(out) > 0 {
+			out = append(out, '\n')
+		}
+		out = append(out, s...)
+	}
+	return out, nil
+}
+
+// nquadString renders an api.NQuad in the RDF format. Only the values with a
+// direct RDF representation are supported.
+func nquadString(nq *api.NQuad) (string, error) {
+	if len(nq.Facets) > 0 {
+		return "", status.Error(codes.Unimplemented, "NQuad facets are not supported over HTTP")
+	}
+
+	var obj string
+	switch {
+	case nq.ObjectId != "":
+		obj = rdfNode(nq.ObjectId)
+	case nq.ObjectValue != nil:
+		v, err := rdfValue(nq.ObjectValue)
+		if err != nil {
+			return "", err
+		}
+		obj = v
+		if nq.Lang != "" {
+			obj += "@" + nq.Lang
+		}
+	default:
+		return "", status.Errorf(codes.InvalidArgument, "NQuad for predicate %s has no object", nq.Predicate)
+	}
+	return fmt.Sprintf("%s <%s> %s .", rdfNode(nq.Subject), nq.Predicate, obj), nil
+}
+
+func rdfNode(id string) string {
+	if strings.HasPrefix(id, "_:") || strings.HasPrefix(id, "uid(") {
+		return id
+	}
+	return "<" + id + ">"
+}
+
+// rdfString quotes s as an N-Quad string literal. Unlike strconv.Quote, it only
+// uses the ECHAR and UCHAR escapes of N-Quads, and keeps the other characters.
+func rdfString(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\f':
+			b.WriteString(`\f`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func rdfValue(v *api.Value) (string, error) {
+	switch val := v.Val.(type) {
+	case *api.Value_DefaultVal:
+		// _STAR_ALL is the value that deletes all the values of a predicate, see DeleteEdges.
+		if val.DefaultVal == "_STAR_ALL" {
+			return "*", nil
+		}
+		return rdfString(val.DefaultVal), nil
+	case *api.Value_StrVal:
+		return rdfString(val.StrVal), nil
+	case *api.Value_IntVal:
+		return `"` + strconv.FormatInt(val.IntVal, 10) + `"^^<xs:int>`, nil
+	case *api.Value_DoubleVal:
+		return `"` + strconv.FormatFloat(val.DoubleVal, 'g', -1, 64) + `"^^<xs:float>`, nil
+	case *api.Value_BoolVal:
+		return `"` + strconv.FormatBool(val.BoolVal) + `"^^<xs:boolean>`, nil
+	default:
+		return "", status.Errorf(codes.Unimplemented, "NQuad values of type %T are not supported over HTTP", v.Val)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Call describes a request of a client to the Dgraph API, as seen by interceptors.
+type Call struct {
+	// Method is the name of the method of the Dgraph API, such as Query,
+	// CommitOrAbort, Alter, RunDQL, Login or AllocateIDs.
+	Method string
+	// Request is the request, such as an *api.Request for Query or an
+	// *api.Operation for Alter. Interceptors may modify it before invoking the call.
+	Request proto.Message
+	// Txn is the transaction of Query and CommitOrAbort requests, if any.
+	Txn *Txn
+	// Attempt is 1 for the first attempt of the request, and is incremented when the
+	// request is retried, e.g. after refreshing an expired JWT.
+	Attempt int
+}
+
+// Invoker sends a call, returning its response.
+type Invoker func(ctx context.Context) (proto.Message, error)
+
+// Interceptor intercepts the calls of a client. It is responsible for invoking the
+// call, and may inspect or modify the context, the request, the response and the
+// error. The response is nil if the call fails. Otherwise, the response returned by
+// an interceptor must be of the type of the response of the method, such as an
+// *api.Response for Query or an *api.Payload for Alter.
+//
+// Interceptors must not use the client, as calls such as Login are made while
+// holding locks of the client.
+type Interceptor func(ctx context.Context, call *Call, invoke Invoker) (proto.Message, error)
+
+// WithInterceptor adds interceptors that wrap every call of the client to the Dgraph
+// API, including each attempt of the queries, mutations and commits of transactions,
+// schema changes, logins, RunDQL and ID allocations, as well as the queries served
+// from the query cache. The first interceptor is the outermost one. Unlike gRPC
+// interceptors, they are told the transaction and the attempt of each call, and they
+// also apply to the HTTP transport.
+func WithInterceptor(interceptors ...Interceptor) ClientOption {
+	return func(o *clientOptions) error {
+		o.interceptors = append(o.interceptors, interceptors...)
+		return nil
+	}
+}
+
+// intercept makes a call using f through the interceptors of the client.
+func intercept[T proto.Message](ctx context.Context, d *Dgraph, call *Call,
+	f func(ctx context.Context) (T, error)) (T, error) {
+
+	if len(d.interceptors) == 0 {
+		return f(ctx)
+	}
+
+	invoke := Invoker(func(ctx context.Context) (proto.Message, error) {
+		resp, err := f(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	})
+	for i := len(d.interceptors) - 1; i >= 0; i-- {
+		ic, next := d.interceptors[i], invoke
+		invoke = func(ctx context.Context) (proto.Message, error) {
+			return ic(ctx, call, next)
+		}
+	}
+
+	resp, err := invoke(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	t, ok := resp.(T)
+	if !ok || !resp.ProtoReflect().IsValid() {
+		var zero T
+		return zero, fmt.Errorf("interceptor returned %T for %s", resp, call.Method)
+	}
+	return t, nil
+}
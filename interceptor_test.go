@@ -0,0 +1,124 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dgraph-io/dgo/v250"
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+func TestInterceptorOffline(t *testing.T) {
+	var mu sync.Mutex
+	var logins int
+	srv := &fakeDgraphServer{
+		login: func(context.Context, *api.LoginRequest) (*api.Response, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			logins++
+			jwt, err := proto.Marshal(&api.Jwt{AccessJwt: fmt.Sprint("access-", logins), RefreshJwt: "refresh"})
+			return &api.Response{Json: jwt}, err
+		},
+		query: func(ctx context.Context, req *api.Request) (*api.Response, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			if md.Get("accessJwt")[0] == "access-1" {
+				return nil, status.Error(codes.Unauthenticated, "Token is expired")
+			}
+			if len(md.Get("tenant")) == 0 {
+				return nil, status.Error(codes.InvalidArgument, "no tenant")
+			}
+			return &api.Response{Json: []byte(`{}`), Txn: &api.TxnContext{StartTs: 5}}, nil
+		},
+	}
+	addr, _ := startFakeServer(t, "127.0.0.1:0", nil, srv)
+
+	var calls []string
+	record := func(ctx context.Context, call *dgo.Call, invoke dgo.Invoker) (proto.Message, error) {
+		resp, err := invoke(ctx)
+		desc := fmt.Sprintf("%s %d", call.Method, call.Attempt)
+		if call.Txn != nil {
+			desc += fmt.Sprintf(" txn %d", call.Txn.StartTs())
+		}
+		if err != nil {
+			desc += " error"
+		} else {
+			require.NotNil(t, resp)
+		}
+		calls = append(calls, desc)
+		return resp, err
+	}
+	guard := func(ctx context.Context, call *dgo.Call, invoke dgo.Invoker) (proto.Message, error) {
+		if op, ok := call.Request.(*api.Operation); ok && op.DropAll {
+			return nil, errors.New("drop all is not allowed")
+		}
+		return invoke(metadata.AppendToOutgoingContext(ctx, "tenant", "t1"))
+	}
+
+	dg, err := dgo.NewClient(addr,
+		dgo.WithGrpcOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		dgo.WithACLCreds("groot", "password"),
+		dgo.WithInterceptor(record, guard))
+	require.NoError(t, err)
+	defer dg.Close()
+
+	ctx := context.Background()
+	txn := dg.NewTxn()
+	_, err = txn.Query(ctx, `{ q(func: uid(1)) { uid } }`)
+	require.NoError(t, err)
+	_, err = txn.Mutate(ctx, &api.Mutation{SetNquads: []byte(`_:a <name> "Alice" .`)})
+	require.NoError(t, err)
+	require.NoError(t, txn.Commit(ctx))
+	require.NoError(t, dg.SetSchema(ctx, "name: string ."))
+	require.EqualError(t, dg.DropAll(ctx), "drop all is not allowed")
+
+	require.Equal(t, []string{
+		"Login 1",
+		"Query 1 txn 0 error",
+		"Login 1",
+		"Query 2 txn 0",
+		"Query 1 txn 5",
+		"CommitOrAbort 1 txn 5",
+		"Alter 1",
+		"Alter 1 error",
+	}, calls)
+}
+
+func TestInterceptorResponseOffline(t *testing.T) {
+	addr, _ := startFakeServer(t, "127.0.0.1:0", nil, &fakeDgraphServer{})
+
+	swap := func(ctx context.Context, call *dgo.Call, invoke dgo.Invoker) (proto.Message, error) {
+		if _, err := invoke(ctx); err != nil {
+			return nil, err
+		}
+		if call.Method == "Alter" {
+			return nil, nil
+		}
+		return &api.Payload{}, nil
+	}
+	dg, err := dgo.NewClient(addr,
+		dgo.WithGrpcOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		dgo.WithInterceptor(swap))
+	require.NoError(t, err)
+	defer dg.Close()
+
+	ctx := context.Background()
+	_, err = dg.NewReadOnlyTxn().Query(ctx, `{ q(func: uid(1)) { uid } }`)
+	require.EqualError(t, err, "interceptor returned *api.Payload for Query")
+	require.EqualError(t, dg.SetSchema(ctx, "name: string ."), "interceptor returned <nil> for Alter")
+}
@@ -0,0 +1,359 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+// RequestKind is a kind of requests, see WithRateLimit and WithOperationTimeout.
+type RequestKind string
+
+const (
+	// RequestQuery is the kind of the queries of transactions, and of the RunDQL
+	// requests without mutations.
+	RequestQuery RequestKind = "query"
+	// RequestMutation is the kind of the requests of transactions with mutations,
+	// and of the RunDQL requests with mutation or upsert blocks.
+	RequestMutation RequestKind = "mutation"
+	// RequestAlter is the kind of schema changes and drop operations.
+	RequestAlter RequestKind = "alter"
+	// RequestCommit is the kind of the commits and aborts of transactions.
+	RequestCommit RequestKind = "commit"
+	// RequestLogin is the kind of logins, including the ones refreshing the JWT.
+	RequestLogin RequestKind = "login"
+)
+
+func (k RequestKind) validate() error {
+	switch k {
+	case RequestQuery, RequestMutation, RequestAlter, RequestCommit, RequestLogin:
+		return nil
+	}
+	return fmt.Errorf("invalid request kind: %q", k)
+}
+
+// WithMaxInFlight limits the number of concurrent requests the client sends to each
+// endpoint. Requests wait until they are allowed, or until their context is done.
+func WithMaxInFlight(n int) ClientOption {
+	return func(o *clientOptions) error {
+		if n <= 0 {
+			return fmt.Errorf("invalid max in-flight requests: %d", n)
+		}
+		o.maxInFlight = n
+		return nil
+	}
+}
+
+// WithRateLimit limits the rate of the requests of the given kind sent by the client
+// to all the endpoints, using a token bucket refilled with perSecond tokens every
+// second and holding up to burst tokens. Requests wait until they are allowed, or
+// fail if their context is done first. This option can be used once for each kind.
+func WithRateLimit(kind RequestKind, perSecond float64, burst int) ClientOption {
+	return func(o *clientOptions) error {
+		if err := kind.validate(); err != nil {
+			return err
+		}
+		if perSecond <= 0 {
+			return fmt.Errorf("invalid rate limit: %v", perSecond)
+		}
+		if burst <= 0 {
+			return fmt.Errorf("invalid rate limit burst: %d", burst)
+		}
+		if o.rateLimits == nil {
+			o.rateLimits = make(map[RequestKind]*tokenBucket)
+		}
+		o.rateLimits[kind] = newTokenBucket(perSecond, burst)
+		return nil
+	}
+}
+
+// WithOperationTimeout sets the default timeout of the requests of the given kind.
+// It only applies to requests whose context has no deadline, and takes precedence
+// over WithRequestTimeout. The time spent waiting for the concurrency and rate
+// limits counts towards the timeout.
+func WithOperationTimeout(kind RequestKind, timeout time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if err := kind.validate(); err != nil {
+			return err
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("invalid %s timeout: %v", kind, timeout)
+		}
+		if o.timeouts == nil {
+			o.timeouts = make(map[RequestKind]time.Duration)
+		}
+		o.timeouts[kind] = timeout
+		return nil
+	}
+}
+
+// WithSchemaTimeout sets the default timeout of the Alter requests changing the
+// schema, which can take long as they may build indexes. It applies like
+// WithOperationTimeout, and takes precedence over the timeout of RequestAlter.
+func WithSchemaTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if timeout <= 0 {
+			return fmt.Errorf("invalid schema timeout: %v", timeout)
+		}
+		o.schemaTimeout = timeout
+		return nil
+	}
+}
+
+// tokenBucket is a token bucket rate limiter. Waiting requests reserve a token in
+// advance, so that they are allowed in the order they arrive.
+type tokenBucket struct {
+	perSecond float64
+	burst     float64
+
+	mu     sync.Mutex
+	tokens float64 // negative when tokens are reserved by waiting requests
+	last   time.Time
+}
+
+func newTokenBucket(perSecond float64, burst int) *tokenBucket {
+	return &tokenBucket{perSecond: perSecond, burst: float64(burst), tokens: float64(burst),
+		last: time.Now()}
+}
+
+// wait blocks until a token is available or ctx is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.perSecond)
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	delay := time.Duration(-b.tokens / b.perSecond * float64(time.Second))
+	b.mu.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Give the reserved token back.
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// limitedClient enforces the concurrency and rate limits and the default timeouts of
+// a client on the requests to one of its endpoints.
+type limitedClient struct {
+	api.DgraphClient
+
+	inFlight      chan struct{} // nil if the requests are not limited
+	rateLimits    map[RequestKind]*tokenBucket
+	timeouts      map[RequestKind]time.Duration
+	schemaTimeout time.Duration
+}
+
+// withLimits returns dc wrapped to enforce the limits in co, if any.
+func withLimits(dc api.DgraphClient, co *clientOptions) api.DgraphClient {
+	if co.maxInFlight == 0 && len(co.rateLimits) == 0 && len(co.timeouts) == 0 &&
+		co.schemaTimeout == 0 {
+
+		return dc
+	}
+	c := &limitedClient{DgraphClient: dc, rateLimits: co.rateLimits, timeouts: co.timeouts,
+		schemaTimeout: co.schemaTimeout}
+	if co.maxInFlight > 0 {
+		c.inFlight = make(chan struct{}, co.maxInFlight)
+	}
+	return c
+}
+
+// acquire waits for a request of the given kind to be allowed, returning a function
+// to call once the request completes. kind is empty for requests of other kinds.
+func (c *limitedClient) acquire(ctx context.Context, kind RequestKind) (func(), error) {
+	if b := c.rateLimits[kind]; b != nil {
+		if err := b.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if c.inFlight == nil {
+		return func() {}, nil
+	}
+	select {
+	case c.inFlight <- struct{}{}:
+		return func() { <-c.inFlight }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// limit calls f once a request of the given kind is allowed. If ctx has no deadline,
+// the request is given the timeout, if any.
+func limit[T any](ctx context.Context, c *limitedClient, kind RequestKind, timeout time.Duration,
+	f func(ctx context.Context) (T, error)) (T, error) {
+
+	if _, ok := ctx.Deadline(); !ok && timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	release, err := c.acquire(ctx, kind)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	defer release()
+	return f(ctx)
+}
+
+func (c *limitedClient) Login(ctx context.Context, in *api.LoginRequest,
+	opts ...grpc.CallOption) (*api.Response, error) {
+
+	return limit(ctx, c, RequestLogin, c.timeouts[RequestLogin],
+		func(ctx context.Context) (*api.Response, error) {
+			return c.DgraphClient.Login(ctx, in, opts...)
+		})
+}
+
+func (c *limitedClient) Query(ctx context.Context, in *api.Request,
+	opts ...grpc.CallOption) (*api.Response, error) {
+
+	kind := RequestQuery
+	if len(in.Mutations) > 0 {
+		kind = RequestMutation
+	}
+	return limit(ctx, c, kind, c.timeouts[kind], func(ctx context.Context) (*api.Response, error) {
+		return c.DgraphClient.Query(ctx, in, opts...)
+	})
+}
+
+func (c *limitedClient) Alter(ctx context.Context, in *api.Operation,
+	opts ...grpc.CallOption) (*api.Payload, error) {
+
+	timeout := c.timeouts[RequestAlter]
+	if in.Schema != "" && c.schemaTimeout > 0 {
+		timeout = c.schemaTimeout
+	}
+	return limit(ctx, c, RequestAlter, timeout, func(ctx context.Context) (*api.Payload, error) {
+		return c.DgraphClient.Alter(ctx, in, opts...)
+	})
+}
+
+func (c *limitedClient) CommitOrAbort(ctx context.Context, in *api.TxnContext,
+	opts ...grpc.CallOption) (*api.TxnContext, error) {
+
+	return limit(ctx, c, RequestCommit, c.timeouts[RequestCommit],
+		func(ctx context.Context) (*api.TxnContext, error) {
+			return c.DgraphClient.CommitOrAbort(ctx, in, opts...)
+		})
+}
+
+func (c *limitedClient) RunDQL(ctx context.Context, in *api.RunDQLRequest,
+	opts ...grpc.CallOption) (*api.Response, error) {
+
+	kind := RequestQuery
+	if !in.ReadOnly && isDQLMutation(in.DqlQuery) {
+		kind = RequestMutation
+	}
+	return limit(ctx, c, kind, c.timeouts[kind], func(ctx context.Context) (*api.Response, error) {
+		return c.DgraphClient.RunDQL(ctx, in, opts...)
+	})
+}
+
+// isDQLMutation reports whether the DQL request q has an upsert block, or a set or
+// delete block. Strings, comments and IRIs are skipped, like in queryPredicates.
+func isDQLMutation(q string) bool {
+	depth := 0
+	for i := 0; i < len(q); {
+		switch c := q[i]; {
+		case c == '"':
+			i++
+			for i < len(q) && q[i] != '"' {
+				if q[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+		case c == '#':
+			for i < len(q) && q[i] != '\n' {
+				i++
+			}
+		case c == '<':
+			j := strings.IndexAny(q[i:], "> \t\n")
+			if j > 0 && q[i+j] == '>' {
+				i += j
+			}
+			i++
+		case c == '{':
+			depth++
+			i++
+		case c == '}':
+			depth--
+			i++
+		case isNameChar(c):
+			j := i
+			for j < len(q) && isNameChar(q[j]) {
+				j++
+			}
+			name := q[i:j]
+			rest := strings.TrimLeft(q[j:], " \t\r\n")
+			switch {
+			case depth == 0 && name == "upsert":
+				return true
+			case depth == 1 && (name == "set" || name == "delete") && strings.HasPrefix(rest, "{"):
+				return true
+			}
+			i = j
+		default:
+			i++
+		}
+	}
+	return false
+}
+
+func (c *limitedClient) AllocateIDs(ctx context.Context, in *api.AllocateIDsRequest,
+	opts ...grpc.CallOption) (*api.AllocateIDsResponse, error) {
+
+	return limit(ctx, c, "", 0, func(ctx context.Context) (*api.AllocateIDsResponse, error) {
+		return c.DgraphClient.AllocateIDs(ctx, in, opts...)
+	})
+}
+
+func (c *limitedClient) CreateNamespace(ctx context.Context, in *api.CreateNamespaceRequest,
+	opts ...grpc.CallOption) (*api.CreateNamespaceResponse, error) {
+
+	return limit(ctx, c, "", 0, func(ctx context.Context) (*api.CreateNamespaceResponse, error) {
+		return c.DgraphClient.CreateNamespace(ctx, in, opts...)
+	})
+}
+
+func (c *limitedClient) DropNamespace(ctx context.Context, in *api.DropNamespaceRequest,
+	opts ...grpc.CallOption) (*api.DropNamespaceResponse, error) {
+
+	return limit(ctx, c, "", 0, func(ctx context.Context) (*api.DropNamespaceResponse, error) {
+		return c.DgraphClient.DropNamespace(ctx, in, opts...)
+	})
+}
+
+func (c *limitedClient) ListNamespaces(ctx context.Context, in *api.ListNamespacesRequest,
+	opts ...grpc.CallOption) (*api.ListNamespacesResponse, error) {
+
+	return limit(ctx, c, "", 0, func(ctx context.Context) (*api.ListNamespacesResponse, error) {
+		return c.DgraphClient.ListNamespaces(ctx, in, opts...)
+	})
+}
@@ -0,0 +1,216 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/dgraph-io/dgo/v250"
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+func TestMaxInFlightOffline(t *testing.T) {
+	release := make(chan struct{})
+	srv := &fakeDgraphServer{
+		query: func(ctx context.Context, req *api.Request) (*api.Response, error) {
+			<-release
+			return &api.Response{Json: []byte(`{}`)}, nil
+		},
+	}
+	addr, _ := startFakeServer(t, "127.0.0.1:0", nil, srv)
+
+	dg, err := dgo.NewClient(addr,
+		dgo.WithGrpcOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		dgo.WithMaxInFlight(2))
+	require.NoError(t, err)
+	defer dg.Close()
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = dg.NewReadOnlyTxn().Query(ctx, `{ q(func: uid(1)) { uid } }`)
+		}()
+	}
+	require.Eventually(t, func() bool { return srv.queries.Load() == 2 }, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	require.EqualValues(t, 2, srv.queries.Load())
+
+	// Requests waiting for their turn fail once their context is done.
+	tctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = dg.NewReadOnlyTxn().Query(tctx, `{ q(func: uid(1)) { uid } }`)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	require.EqualValues(t, 4, srv.queries.Load())
+}
+
+func TestRateLimitOffline(t *testing.T) {
+	addr, _ := startFakeServer(t, "127.0.0.1:0", nil, &fakeDgraphServer{})
+
+	dg, err := dgo.NewClient(addr,
+		dgo.WithGrpcOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		dgo.WithRateLimit(dgo.RequestAlter, 20, 1))
+	require.NoError(t, err)
+	defer dg.Close()
+
+	ctx := context.Background()
+	start := time.Now()
+	for range 3 {
+		require.NoError(t, dg.SetSchema(ctx, "name: string ."))
+	}
+	require.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+	// Requests waiting for a token fail once their context is done.
+	tctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, dg.SetSchema(tctx, "name: string ."), context.DeadlineExceeded)
+
+	// Other kinds of requests are not limited, 10 alters would take 450ms.
+	start = time.Now()
+	for range 10 {
+		_, err := dg.NewReadOnlyTxn().Query(ctx, `{ q(func: uid(1)) { uid } }`)
+		require.NoError(t, err)
+	}
+	require.Less(t, time.Since(start), 400*time.Millisecond)
+}
+
+func TestOperationTimeoutRunDQLOffline(t *testing.T) {
+	srv := &fakeDgraphServer{
+		runDQL: func(context.Context, *api.RunDQLRequest) (*api.Response, error) {
+			return &api.Response{Json: []byte(`{}`)}, nil
+		},
+	}
+	addr, _ := startFakeServer(t, "127.0.0.1:0", nil, srv)
+
+	dg, err := dgo.NewClient(addr,
+		dgo.WithGrpcOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		dgo.WithOperationTimeout(dgo.RequestMutation, time.Nanosecond))
+	require.NoError(t, err)
+	defer dg.Close()
+
+	// RunDQL requests are mutations only if they have mutations.
+	ctx := context.Background()
+	for _, q := range []string{
+		`{ set { _:a <name> "A" . } }`,
+		`{ delete { <0x1> <name> * . } }`,
+		`upsert { query { v as var(func: eq(name, "A")) } mutation { set { uid(v) <age> "1" . } } }`,
+	} {
+		_, err := dg.RunDQL(ctx, q)
+		require.Equal(t, codes.DeadlineExceeded, status.Code(err), q)
+	}
+	for _, q := range []string{
+		`{ set(func: has(name)) { name } }`,
+		`{ q(func: eq(name, "{ set { }")) { name } } # upsert { }`,
+		`query q($n: string) { q(func: eq(<http://x.org/a#b>, $n)) { delete: name } }`,
+	} {
+		_, err := dg.RunDQL(ctx, q)
+		require.NoError(t, err, q)
+	}
+}
+
+func TestOperationTimeoutOffline(t *testing.T) {
+	wait := func(ctx context.Context, d time.Duration) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+			return nil
+		}
+	}
+	srv := &fakeDgraphServer{
+		query: func(ctx context.Context, req *api.Request) (*api.Response, error) {
+			return &api.Response{Json: []byte(`{}`)}, wait(ctx, 100*time.Millisecond)
+		},
+		alter: func(ctx context.Context, op *api.Operation) (*api.Payload, error) {
+			return &api.Payload{}, wait(ctx, 100*time.Millisecond)
+		},
+	}
+	addr, _ := startFakeServer(t, "127.0.0.1:0", nil, srv)
+
+	dg, err := dgo.NewClient(addr,
+		dgo.WithGrpcOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		dgo.WithRequestTimeout(time.Second),
+		dgo.WithOperationTimeout(dgo.RequestQuery, 20*time.Millisecond),
+		dgo.WithOperationTimeout(dgo.RequestAlter, 20*time.Millisecond),
+		dgo.WithSchemaTimeout(time.Second))
+	require.NoError(t, err)
+	defer dg.Close()
+
+	ctx := context.Background()
+	_, err = dg.NewReadOnlyTxn().Query(ctx, `{ q(func: uid(1)) { uid } }`)
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	require.Equal(t, codes.DeadlineExceeded, status.Code(dg.DropPredicate(ctx, "name")))
+
+	// Schema changes use their own timeout.
+	require.NoError(t, dg.SetSchema(ctx, "name: string ."))
+
+	// The deadline of the caller takes precedence.
+	tctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, err = dg.NewReadOnlyTxn().Query(tctx, `{ q(func: uid(1)) { uid } }`)
+	require.NoError(t, err)
+}
+
+func TestOperationTimeoutLoginOffline(t *testing.T) {
+	srv := &fakeDgraphServer{
+		login: func(ctx context.Context, req *api.LoginRequest) (*api.Response, error) {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Second):
+				return nil, status.Error(codes.Unauthenticated, "slow login")
+			}
+		},
+	}
+	addr, _ := startFakeServer(t, "127.0.0.1:0", nil, srv)
+
+	// The login made at startup uses the timeout of requests.
+	start := time.Now()
+	_, err := dgo.NewClient(addr,
+		dgo.WithGrpcOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		dgo.WithACLCreds("groot", "password"),
+		dgo.WithRequestTimeout(50*time.Millisecond))
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestLimitOptions(t *testing.T) {
+	tests := []struct {
+		opt dgo.ClientOption
+		err string
+	}{
+		{opt: dgo.WithMaxInFlight(0), err: "invalid max in-flight requests: 0"},
+		{opt: dgo.WithRateLimit("upsert", 1, 1), err: `invalid request kind: "upsert"`},
+		{opt: dgo.WithRateLimit(dgo.RequestQuery, 0, 1), err: "invalid rate limit: 0"},
+		{opt: dgo.WithRateLimit(dgo.RequestQuery, 1, 0), err: "invalid rate limit burst: 0"},
+		{opt: dgo.WithOperationTimeout("upsert", time.Second), err: `invalid request kind: "upsert"`},
+		{opt: dgo.WithOperationTimeout(dgo.RequestCommit, 0), err: "invalid commit timeout: 0s"},
+		{opt: dgo.WithSchemaTimeout(-time.Second), err: "invalid schema timeout: -1s"},
+	}
+
+	for _, tc := range tests {
+		_, err := dgo.NewClient("127.0.0.1:9180", tc.opt)
+		require.EqualError(t, err, tc.err)
+	}
+}
@@ -8,6 +8,8 @@ package dgo
 import (
 	"context"
 
+	"google.golang.org/protobuf/proto"
+
 	"github.com/dgraph-io/dgo/v250/protos/api"
 )
 
@@ -70,17 +72,27 @@ func (d *Dgraph) RunDQLWithVars(ctx context.Context, q string,
 
 	req := &api.RunDQLRequest{DqlQuery: q, Vars: vars,
 		ReadOnly: topts.readOnly, BestEffort: topts.bestEffort, RespFormat: topts.respFormat}
-	return doWithRetryLogin(ctx, d, func(dc api.DgraphClient) (*api.Response, error) {
-		return dc.RunDQL(d.getContext(ctx), req)
-	})
+	resp, err := doWithRetryLogin(ctx, d, "RunDQL", req,
+		func(ctx context.Context, dc api.DgraphClient) (*api.Response, error) {
+			return dc.RunDQL(ctx, req)
+		})
+	if err != nil {
+		return nil, err
+	}
+	// Requests that are not read-only may commit mutations.
+	if !req.ReadOnly && d.cache != nil {
+		d.cache.invalidate(resp.GetTxn().GetPreds())
+	}
+	return resp, nil
 }
 
 // CreateNamespace creates a new namespace with the given name and password for groot user.
 func (d *Dgraph) CreateNamespace(ctx context.Context) (uint64, error) {
 	req := &api.CreateNamespaceRequest{}
-	resp, err := doWithRetryLogin(ctx, d, func(dc api.DgraphClient) (*api.CreateNamespaceResponse, error) {
-		return dc.CreateNamespace(d.getContext(ctx), req)
-	})
+	resp, err := doWithRetryLogin(ctx, d, "CreateNamespace", req,
+		func(ctx context.Context, dc api.DgraphClient) (*api.CreateNamespaceResponse, error) {
+			return dc.CreateNamespace(ctx, req)
+		})
 	if err != nil {
 		return 0, err
 	}
@@ -90,33 +102,62 @@ func (d *Dgraph) CreateNamespace(ctx context.Context) (uint64, error) {
 // DropNamespace deletes the namespace with the given name.
 func (d *Dgraph) DropNamespace(ctx context.Context, nsID uint64) error {
 	req := &api.DropNamespaceRequest{Namespace: nsID}
-	_, err := doWithRetryLogin(ctx, d, func(dc api.DgraphClient) (*api.DropNamespaceResponse, error) {
-		return dc.DropNamespace(d.getContext(ctx), req)
-	})
+	_, err := doWithRetryLogin(ctx, d, "DropNamespace", req,
+		func(ctx context.Context, dc api.DgraphClient) (*api.DropNamespaceResponse, error) {
+			return dc.DropNamespace(ctx, req)
+		})
+	if err == nil && d.cache != nil {
+		d.cache.clear()
+	}
 	return err
 }
 
 // ListNamespaces returns a map of namespace names to their details.
 func (d *Dgraph) ListNamespaces(ctx context.Context) (map[uint64]*api.Namespace, error) {
-	resp, err := doWithRetryLogin(ctx, d, func(dc api.DgraphClient) (*api.ListNamespacesResponse, error) {
-		return dc.ListNamespaces(d.getContext(ctx), &api.ListNamespacesRequest{})
-	})
+	req := &api.ListNamespacesRequest{}
+	resp, err := doWithRetryLogin(ctx, d, "ListNamespaces", req,
+		func(ctx context.Context, dc api.DgraphClient) (*api.ListNamespacesResponse, error) {
+			return dc.ListNamespaces(ctx, req)
+		})
 	if err != nil {
 		return nil, err
 	}
 	return resp.Namespaces, nil
 }
 
-func doWithRetryLogin[T any](ctx context.Context, d *Dgraph,
-	f func(dc api.DgraphClient) (*T, error)) (*T, error) {
+// ForNamespace logs into the given namespace and returns a handle to the cluster
+// that sends requests as the logged in user. The handle shares the connections of d,
+// but has its own JWT, which is refreshed independently of d and of other handles
+// when it expires. This allows serving multiple namespaces concurrently over a single
+// set of connections, unlike LoginIntoNamespace which changes the JWT used by all the
+// users of d. Closing the handle is a no-op, the connections are closed by d.Close.
+func (d *Dgraph) ForNamespace(ctx context.Context, nsID uint64, user, password string) (*Dgraph, error) {
+	h := &Dgraph{dc: d.dc, endpoints: d.endpoints, lbPolicy: d.lbPolicy, httpAuth: d.httpAuth,
+		cache: d.cache, hedger: d.hedger, interceptors: d.interceptors}
+	if err := h.login(ctx, user, password, nsID); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func doWithRetryLogin[T proto.Message](ctx context.Context, d *Dgraph, method string,
+	req proto.Message, f func(ctx context.Context, dc api.DgraphClient) (T, error)) (T, error) {
 
 	dc := d.anyClient()
-	resp, err := f(dc)
+	call := func(attempt int) (T, error) {
+		c := &Call{Method: method, Request: req, Attempt: attempt}
+		return intercept(ctx, d, c, func(ctx context.Context) (T, error) {
+			return f(d.getContext(ctx), dc)
+		})
+	}
+
+	resp, err := call(1)
 	if isJwtExpired(err) {
 		if err := d.retryLogin(ctx); err != nil {
-			return nil, err
+			var zero T
+			return zero, err
 		}
-		return f(dc)
+		return call(2)
 	}
 	return resp, err
 }
@@ -0,0 +1,140 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dgraph-io/dgo/v250"
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+func TestForNamespaceOffline(t *testing.T) {
+	var mu sync.Mutex
+	logins := map[string]int{}
+	expired := map[string]bool{"access-2-1": true}
+	srv := &fakeDgraphServer{
+		login: func(_ context.Context, req *api.LoginRequest) (*api.Response, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			ns := fmt.Sprint(req.Namespace)
+			if req.RefreshToken != "" {
+				ns = req.RefreshToken[len("refresh-"):]
+			} else if req.Password != "password-"+ns {
+				return nil, status.Error(codes.Unauthenticated, "invalid password")
+			}
+			logins[ns]++
+			jwt, err := proto.Marshal(&api.Jwt{
+				AccessJwt:  fmt.Sprintf("access-%s-%d", ns, logins[ns]),
+				RefreshJwt: "refresh-" + ns,
+			})
+			return &api.Response{Json: jwt}, err
+		},
+		query: func(ctx context.Context, req *api.Request) (*api.Response, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			if len(md.Get("accessJwt")) == 0 {
+				return nil, status.Error(codes.Unauthenticated, "no accessJwt available")
+			}
+			jwt := md.Get("accessJwt")[0]
+
+			mu.Lock()
+			defer mu.Unlock()
+			if expired[jwt] {
+				return nil, status.Error(codes.Unauthenticated, "Token is expired")
+			}
+			return &api.Response{Json: []byte(`{"jwt": "` + jwt + `"}`)}, nil
+		},
+	}
+	addr, _ := startFakeServer(t, "127.0.0.1:0", nil, srv)
+
+	dg, err := dgo.NewClient(addr, dgo.WithGrpcOption(grpc.WithTransportCredentials(insecure.NewCredentials())))
+	require.NoError(t, err)
+	defer dg.Close()
+
+	ctx := context.Background()
+	_, err = dg.ForNamespace(ctx, 1, "groot", "wrong")
+	require.ErrorContains(t, err, "invalid password")
+
+	var handles []*dgo.Dgraph
+	for ns := range uint64(3) {
+		h, err := dg.ForNamespace(ctx, ns+1, "groot", fmt.Sprintf("password-%d", ns+1))
+		require.NoError(t, err)
+		handles = append(handles, h)
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, len(handles))
+	for i, h := range handles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := h.NewReadOnlyTxn().Query(ctx, "{}")
+			if err == nil {
+				results[i] = string(resp.Json)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The expired token of namespace 2 was refreshed without affecting the others.
+	require.Equal(t, []string{
+		`{"jwt": "access-1-1"}`,
+		`{"jwt": "access-2-2"}`,
+		`{"jwt": "access-3-1"}`,
+	}, results)
+	require.Empty(t, dg.GetJwt().AccessJwt)
+
+	// Closing a handle doesn't close the shared connections.
+	handles[0].Close()
+	_, err = handles[1].NewReadOnlyTxn().Query(ctx, "{}")
+	require.NoError(t, err)
+
+	resp, err := dg.NewReadOnlyTxn().Query(ctx, "{}")
+	require.Nil(t, resp)
+	require.ErrorContains(t, err, "no accessJwt available")
+}
+
+func TestForNamespace(t *testing.T) {
+	dg, cancel := getDgraphClient()
+	defer cancel()
+
+	ctx := context.Background()
+	require.NoError(t, dg.DropAll(ctx))
+	nsID, err := dg.CreateNamespace(ctx)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, dg.DropNamespace(ctx, nsID)) }()
+
+	ns, err := dg.ForNamespace(ctx, nsID, "groot", "password")
+	require.NoError(t, err)
+	require.NoError(t, ns.SetSchema(ctx, `name: string @index(exact) .`))
+	_, err = ns.NewTxn().Mutate(ctx, &api.Mutation{
+		SetNquads: []byte(`_:a <name> "Alice" .`),
+		CommitNow: true,
+	})
+	require.NoError(t, err)
+
+	query := `{ q(func: has(name)) { name } }`
+	resp, err := ns.NewReadOnlyTxn().Query(ctx, query)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"q": [{"name": "Alice"}]}`, string(resp.Json))
+
+	// The parent client is still logged into the galaxy namespace.
+	resp, err = dg.NewReadOnlyTxn().Query(ctx, query)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"q": []}`, string(resp.Json))
+}
@@ -11,26 +11,55 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
 
 	"github.com/dgraph-io/dgo/v250/protos/api"
 )
 
 const (
-	dgraphScheme     = "dgraph"
-	cloudAPIKeyParam = "apikey"      // optional parameter for providing a Dgraph Cloud API key
-	bearerTokenParam = "bearertoken" // optional parameter for providing an access token
-	sslModeParam     = "sslmode"     // optional parameter for providing a Dgraph SSL mode
-	namespaceParam   = "namespace"   // optional parameter for providing a Dgraph namespace ID
-	sslModeDisable   = "disable"
-	sslModeRequire   = "require"
-	sslModeVerifyCA  = "verify-ca"
+	dgraphScheme       = "dgraph"
+	httpScheme         = "http"
+	httpsScheme        = "https"
+	cloudAPIKeyParam   = "apikey"        // optional parameter for providing a Dgraph Cloud API key
+	bearerTokenParam   = "bearertoken"   // optional parameter for providing an access token
+	sslModeParam       = "sslmode"       // optional parameter for providing a Dgraph SSL mode
+	sslRootCertParam   = "sslrootcert"   // optional parameter for providing a CA certificate file
+	sslCertParam       = "sslcert"       // optional parameter for providing a client certificate file
+	sslKeyParam        = "sslkey"        // optional parameter for providing a client key file
+	sslReloadParam     = "sslreload"     // optional parameter for reloading certificate files
+	sslServerNameParam = "sslservername" // optional parameter for overriding TLS server names
+	loadBalanceParam   = "loadbalance"   // optional parameter for providing a load balancing policy
+	namespaceParam     = "namespace"     // optional parameter for providing a Dgraph namespace ID
+
+	timeoutParam          = "timeout"          // optional parameter for providing a default request timeout
+	connectTimeoutParam   = "connecttimeout"   // optional parameter for providing a connect timeout
+	keepaliveParam        = "keepalive"        // optional parameter for providing a keepalive ping interval
+	keepaliveTimeoutParam = "keepalivetimeout" // optional parameter for providing a keepalive ping timeout
+	maxRecvMsgSizeParam   = "maxrecvmsgsize"   // optional parameter for providing a max receive message size
+	maxSendMsgSizeParam   = "maxsendmsgsize"   // optional parameter for providing a max send message size
+	compressionParam      = "compression"      // optional parameter for providing a compression algorithm
+	maxRetriesParam       = "maxretries"       // optional parameter for providing a max number of retries
+
+	compressionGzip = "gzip"
+	compressionNone = "none"
+
+	sslModeDisable    = "disable"
+	sslModeRequire    = "require"
+	sslModeVerifyCA   = "verify-ca"
+	sslModeVerifyFull = "verify-full"
 )
 
 type bearerCreds struct {
@@ -47,20 +76,59 @@ func (a *bearerCreds) RequireTransportSecurity() bool {
 	return true
 }
 
+func (a *bearerCreds) setHTTPAuth(_ context.Context, h http.Header) error {
+	h.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
 type clientOptions struct {
 	namespace uint64
 	gopts     []grpc.DialOption
 	username  string
 	password  string
+	tlsConfig *tls.Config
+
+	verifyCAOnly   bool
+	caFile         string
+	certFile       string
+	keyFile        string
+	reloadInterval time.Duration
+	serverNames    map[string]string
+	lbPolicy       LoadBalancingPolicy
+	httpAuth       []httpAuth
+
+	useHTTP        bool
+	requestTimeout time.Duration
+	cache          *queryCache
+	hedger         *hedger
+	interceptors   []Interceptor
+	maxInFlight    int
+	rateLimits     map[RequestKind]*tokenBucket
+	timeouts       map[RequestKind]time.Duration
+	schemaTimeout  time.Duration
+
+	breakerFailures int
+	breakerCooldown time.Duration
 }
 
 // ClientOption is a function that modifies the client options.
 type ClientOption func(*clientOptions) error
 
+// tls returns the TLS configuration for the client, creating it if needed.
+// If set, it is used for the transport credentials of all the connections.
+func (o *clientOptions) tls() *tls.Config {
+	if o.tlsConfig == nil {
+		o.tlsConfig = &tls.Config{}
+	}
+	return o.tlsConfig
+}
+
 // WithDgraphAPIKey will use the provided API key for authentication for Dgraph Cloud.
 func WithDgraphAPIKey(apiKey string) ClientOption {
 	return func(o *clientOptions) error {
-		o.gopts = append(o.gopts, grpc.WithPerRPCCredentials(&authCreds{token: apiKey}))
+		creds := &authCreds{token: apiKey}
+		o.gopts = append(o.gopts, grpc.WithPerRPCCredentials(creds))
+		o.httpAuth = append(o.httpAuth, creds)
 		return nil
 	}
 }
@@ -69,14 +137,29 @@ func WithDgraphAPIKey(apiKey string) ClientOption {
 // in the HTTP Authorization header for authentication against a Dgraph Cluster.
 func WithBearerToken(token string) ClientOption {
 	return func(o *clientOptions) error {
-		o.gopts = append(o.gopts, grpc.WithPerRPCCredentials(&bearerCreds{token: token}))
+		creds := &bearerCreds{token: token}
+		o.gopts = append(o.gopts, grpc.WithPerRPCCredentials(creds))
+		o.httpAuth = append(o.httpAuth, creds)
 		return nil
 	}
 }
 
+// WithSkipTLSVerify will set up a TLS connection with Dgraph cluster without
+// verifying the certificate presented by the server.
 func WithSkipTLSVerify() ClientOption {
 	return func(o *clientOptions) error {
-		o.gopts = append(o.gopts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})))
+		o.tls().InsecureSkipVerify = true
+		return nil
+	}
+}
+
+// WithVerifyCAOnly will verify that the certificate presented by the Dgraph cluster
+// is signed by a trusted CA, without verifying that it matches the server hostname.
+// This is what sslmode=verify-ca does.
+func WithVerifyCAOnly() ClientOption {
+	return func(o *clientOptions) error {
+		o.tls()
+		o.verifyCAOnly = true
 		return nil
 	}
 }
@@ -89,12 +172,117 @@ func WithSystemCertPool() ClientOption {
 			return fmt.Errorf("failed to create system cert pool: %w", err)
 		}
 
-		creds := credentials.NewClientTLSFromCert(pool, "")
-		o.gopts = append(o.gopts, grpc.WithTransportCredentials(creds))
+		o.tls().RootCAs = pool
+		return nil
+	}
+}
+
+// WithTLSConfig will use a copy of the provided TLS configuration to set up a TLS
+// connection with Dgraph cluster. It replaces the TLS configuration set by options
+// applied before it, and options applied after it modify the copy.
+func WithTLSConfig(cfg *tls.Config) ClientOption {
+	return func(o *clientOptions) error {
+		o.tlsConfig = cfg.Clone()
+		o.caFile, o.certFile, o.keyFile = "", "", ""
+		return nil
+	}
+}
+
+// WithCACertFile will verify the certificate presented by the Dgraph cluster against
+// the PEM encoded CA certificates in the given file, instead of the system cert pool.
+func WithCACertFile(caFile string) ClientOption {
+	return func(o *clientOptions) error {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return err
+		}
+
+		o.tls().RootCAs = pool
+		o.caFile = caFile
+		return nil
+	}
+}
+
+// WithClientCertificate will present the certificate and key, read from the given PEM
+// encoded files, to the Dgraph cluster for mutual TLS authentication.
+func WithClientCertificate(certFile, keyFile string) ClientOption {
+	return func(o *clientOptions) error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %w", err)
+		}
+
+		o.tls().Certificates = []tls.Certificate{cert}
+		o.certFile, o.keyFile = certFile, keyFile
+		return nil
+	}
+}
+
+// WithTLSReload will reload the files provided using WithCACertFile and
+// WithClientCertificate when they change, so that rotated certificates are used
+// for new connections without recreating the client. The files are checked at
+// most once per interval, when a new connection is established. If the new files
+// can't be loaded, the previous certificates continue to be used.
+func WithTLSReload(interval time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if interval <= 0 {
+			return fmt.Errorf("invalid TLS reload interval: %v", interval)
+		}
+		o.reloadInterval = interval
+		return nil
+	}
+}
+
+// WithServerName overrides the server name used to verify the hostname in the
+// certificate presented by the Dgraph cluster.
+func WithServerName(serverName string) ClientOption {
+	return func(o *clientOptions) error {
+		o.tls().ServerName = serverName
+		return nil
+	}
+}
+
+// WithEndpointServerName overrides the server name used to verify the certificate
+// presented by the given endpoint, taking precedence over WithServerName.
+func WithEndpointServerName(endpoint, serverName string) ClientOption {
+	return func(o *clientOptions) error {
+		if o.serverNames == nil {
+			o.serverNames = make(map[string]string)
+		}
+		o.tls()
+		o.serverNames[endpoint] = serverName
+		return nil
+	}
+}
+
+// WithLoadBalancingPolicy sets how the client picks one of its endpoints for
+// each transaction or request.
+func WithLoadBalancingPolicy(policy LoadBalancingPolicy) ClientOption {
+	return func(o *clientOptions) error {
+		switch policy {
+		case LoadBalanceRandom, LoadBalanceRoundRobin:
+		default:
+			return fmt.Errorf("invalid load balancing policy: %s (must be one of %s, %s)",
+				policy, LoadBalanceRandom, LoadBalanceRoundRobin)
+		}
+		o.lbPolicy = policy
 		return nil
 	}
 }
 
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no valid certificates found in CA certificate file [%s]", caFile)
+	}
+	return pool, nil
+}
+
 // WithNamespace logs into the given namespace.
 func WithNamespace(nsID uint64) ClientOption {
 	return func(o *clientOptions) error {
@@ -121,6 +309,159 @@ func WithResponseFormat(respFormat api.Request_RespFormat) TxnOption {
 	}
 }
 
+// WithRequestTimeout sets the default timeout for requests made by the client.
+// It only applies to requests whose context has no deadline. WithOperationTimeout
+// and WithSchemaTimeout set the timeouts of some kinds of requests instead.
+func WithRequestTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if timeout <= 0 {
+			return fmt.Errorf("invalid request timeout: %v", timeout)
+		}
+		o.gopts = append(o.gopts, grpc.WithChainUnaryInterceptor(timeoutInterceptor(timeout)))
+		o.requestTimeout = timeout
+		return nil
+	}
+}
+
+func timeoutInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// WithConnectTimeout sets the timeout for establishing a connection to an endpoint.
+func WithConnectTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if timeout <= 0 {
+			return fmt.Errorf("invalid connect timeout: %v", timeout)
+		}
+		o.gopts = append(o.gopts, grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.DefaultConfig,
+			MinConnectTimeout: timeout,
+		}))
+		return nil
+	}
+}
+
+// WithKeepalive sends keepalive pings on connections that have been idle for
+// the given interval, closing a connection if a ping isn't acknowledged within
+// the timeout. A zero timeout uses the gRPC default.
+func WithKeepalive(interval, timeout time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if interval <= 0 || timeout < 0 {
+			return fmt.Errorf("invalid keepalive parameters: interval %v, timeout %v", interval, timeout)
+		}
+		o.gopts = append(o.gopts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    interval,
+			Timeout: timeout,
+		}))
+		return nil
+	}
+}
+
+// WithMaxRecvMsgSize sets the maximum size in bytes of a response the client can
+// receive. The gRPC default is 4MB, which can be too small for large query results.
+func WithMaxRecvMsgSize(size int) ClientOption {
+	return func(o *clientOptions) error {
+		if size <= 0 {
+			return fmt.Errorf("invalid max receive message size: %d", size)
+		}
+		o.gopts = append(o.gopts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(size)))
+		return nil
+	}
+}
+
+// WithMaxSendMsgSize sets the maximum size in bytes of a request the client can send.
+func WithMaxSendMsgSize(size int) ClientOption {
+	return func(o *clientOptions) error {
+		if size <= 0 {
+			return fmt.Errorf("invalid max send message size: %d", size)
+		}
+		o.gopts = append(o.gopts, grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(size)))
+		return nil
+	}
+}
+
+// WithGzipCompression compresses requests using gzip.
+func WithGzipCompression() ClientOption {
+	return func(o *clientOptions) error {
+		o.gopts = append(o.gopts, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
+		return nil
+	}
+}
+
+// RetryPolicy configures gRPC to retry requests that fail with the Unavailable status
+// code, e.g. when an Alpha is restarting. Zero values are replaced by the defaults.
+type RetryPolicy struct {
+	// Methods are the names of the methods of the Dgraph API whose requests are
+	// retried, CheckVersion, Login and ListNamespaces by default. Only idempotent
+	// methods should be retried: a request may fail with Unavailable after being
+	// applied, so retrying Query requests carrying mutations committed with
+	// CommitNow, CommitOrAbort or RunDQL may apply them twice.
+	Methods []string
+	// MaxRetries is the number of retries after the first attempt. gRPC caps the
+	// total number of attempts to 5.
+	MaxRetries int
+	// InitialBackoff is the maximum delay before the first retry, 100ms by default.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum delay between retries, 1s by default.
+	MaxBackoff time.Duration
+	// BackoffMultiplier is applied to the delay after each retry, 2 by default.
+	BackoffMultiplier float64
+}
+
+// WithRetryPolicy retries the requests of the methods of the policy that fail with
+// the Unavailable status code. It sets the default gRPC service config of the client.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(o *clientOptions) error {
+		if policy.MaxRetries <= 0 {
+			return fmt.Errorf("invalid max retries: %d", policy.MaxRetries)
+		}
+		if len(policy.Methods) == 0 {
+			policy.Methods = []string{"CheckVersion", "Login", "ListNamespaces"}
+		}
+		names := make([]string, len(policy.Methods))
+		for i, method := range policy.Methods {
+			if !slices.ContainsFunc(api.Dgraph_ServiceDesc.Methods, func(m grpc.MethodDesc) bool {
+				return m.MethodName == method
+			}) {
+				return fmt.Errorf("invalid retried method: %q", method)
+			}
+			names[i] = fmt.Sprintf(`{"service": "api.Dgraph", "method": %q}`, method)
+		}
+		if policy.InitialBackoff == 0 {
+			policy.InitialBackoff = 100 * time.Millisecond
+		}
+		if policy.MaxBackoff == 0 {
+			policy.MaxBackoff = time.Second
+		}
+		if policy.BackoffMultiplier == 0 {
+			policy.BackoffMultiplier = 2
+		}
+
+		serviceConfig := fmt.Sprintf(`{"methodConfig": [{
+			"name": [%s],
+			"retryPolicy": {
+				"maxAttempts": %d,
+				"initialBackoff": "%.3fs",
+				"maxBackoff": "%.3fs",
+				"backoffMultiplier": %g,
+				"retryableStatusCodes": ["UNAVAILABLE"]
+			}
+		}]}`, strings.Join(names, ", "), policy.MaxRetries+1, policy.InitialBackoff.Seconds(), policy.MaxBackoff.Seconds(),
+			policy.BackoffMultiplier)
+		o.gopts = append(o.gopts, grpc.WithDefaultServiceConfig(serviceConfig))
+		return nil
+	}
+}
+
 // WithGrpcOption will add a grpc.DialOption to the client.
 // This is useful for setting custom  grpc options.
 func WithGrpcOption(opt grpc.DialOption) ClientOption {
@@ -131,23 +472,82 @@ func WithGrpcOption(opt grpc.DialOption) ClientOption {
 }
 
 // Open creates a new Dgraph client by parsing a connection string of the form:
-// dgraph://<optional-login>:<optional-password>@<host>:<port>?<optional-params>
-// For example `dgraph://localhost:9080?sslmode=require`
+// dgraph://<optional-login>:<optional-password>@<host>:<port>[,<host>:<port>...]?<optional-params>
+// For example `dgraph://localhost:9080?sslmode=require`. If multiple comma separated
+// hosts are provided, the client is created using NewRoundRobinClient. The http://
+// and https:// schemes connect to the HTTP endpoints of the hosts using
+// WithHTTPTransport, e.g. `https://localhost:8080`; TLS is required for https://
+// and the sslmode defaults to verify-full.
 //
 // Parameters:
 // - apikey: a Dgraph Cloud API key for authentication
 // - bearertoken: a token for bearer authentication
-// - sslmode: SSL connection mode (options: disable, require, verify-ca)
-//   - disable: No TLS (default)
-//   - require: Use TLS but skip certificate verification
-//   - verify-ca: Use TLS and verify the certificate against system CA
+// - sslrootcert: path to the CA certificates used to verify the server instead of system CA
+// - sslcert: path to the client certificate for mutual TLS, requires sslkey
+// - sslkey: path to the client private key for mutual TLS, requires sslcert
+// - sslreload: interval for checking the certificate files for changes, e.g. 1m
+// - sslservername: server name used to verify the certificates, either one for all
+// the hosts, or a comma separated list with one for each host
+// - loadbalance: policy for picking a host (options: random, roundrobin)
+// - timeout: default timeout for requests without a deadline, e.g. 30s
+// - connecttimeout: timeout for establishing a connection, e.g. 10s
+// - keepalive: interval of keepalive pings on idle connections, e.g. 30s
+// - keepalivetimeout: timeout for acknowledging a keepalive ping, requires keepalive
+// - maxrecvmsgsize: max size in bytes of a response, 4MB by default
+// - maxsendmsgsize: max size in bytes of a request
+// - compression: request compression (options: none, gzip)
+// - maxretries: number of retries for requests failing with the Unavailable status code
+// - sslmode: SSL connection mode (options: disable, require, verify-ca, verify-full)
+//   - disable: No TLS (default, unless a certificate parameter is provided)
+//   - require: Use TLS but skip certificate verification, unless sslrootcert is provided
+//     in which case the certificate is verified as with verify-ca
+//   - verify-ca: Use TLS and verify the certificate against the CA certificates, but not
+//     the server hostname
+//   - verify-full: Use TLS and verify the certificate along with the server hostname
+//     (default if a certificate parameter is provided)
 //
 // If credentials are provided, Open connects to the gRPC endpoint and authenticates the user.
 // An error can be returned if the Dgraph cluster is not yet ready to accept requests--the text
 // of the error in this case will contain the string "Please retry".
 func Open(connStr string) (*Dgraph, error) {
+	cs, err := parseConnString(connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := cs.opts
+	if cs.hasUser {
+		if cs.username == "" || cs.password == "" {
+			return nil, errors.New("invalid connection string: both username and password must be provided")
+		}
+		opts = append(opts, WithACLCreds(cs.username, cs.password))
+	}
+	return NewRoundRobinClient(cs.hosts, opts...)
+}
+
+// connString holds the result of parsing a connection string.
+type connString struct {
+	hosts []string
+	// opts holds the options for all the parameters of the connection string,
+	// but not for the ACL credentials.
+	opts []ClientOption
+
+	hasUser  bool
+	username string
+	password string
+
+	hasAPIKey      bool
+	hasBearerToken bool
+}
+
+func parseConnString(connStr string) (*connString, error) {
 	u, err := url.Parse(connStr)
 	if err != nil {
+		// Don't include the connection string in the error as it may contain secrets.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
 		return nil, fmt.Errorf("invalid connection string: %w", err)
 	}
 
@@ -159,22 +559,36 @@ func Open(connStr string) (*Dgraph, error) {
 	apiKey := params.Get(cloudAPIKeyParam)
 	bearerToken := params.Get(bearerTokenParam)
 	sslMode := params.Get(sslModeParam)
+	sslRootCert := params.Get(sslRootCertParam)
+	sslCert := params.Get(sslCertParam)
+	sslKey := params.Get(sslKeyParam)
+	sslReload := params.Get(sslReloadParam)
+	serverNames := params.Get(sslServerNameParam)
+	lbPolicy := params.Get(loadBalanceParam)
 	nsID := params.Get(namespaceParam)
 
-	if u.Scheme != dgraphScheme {
-		return nil, fmt.Errorf("invalid scheme: must start with %s://", dgraphScheme)
+	opts := []ClientOption{}
+	switch u.Scheme {
+	case dgraphScheme:
+	case httpScheme, httpsScheme:
+		opts = append(opts, WithHTTPTransport())
+	default:
+		return nil, fmt.Errorf("invalid scheme: must start with %s://, %s:// or %s://",
+			dgraphScheme, httpScheme, httpsScheme)
 	}
 	if apiKey != "" && bearerToken != "" {
 		return nil, errors.New("invalid connection string: both apikey and bearertoken cannot be provided")
 	}
-	if len(strings.Split(u.Host, ":")) != 2 {
-		return nil, errors.New("invalid connection string: host url must have both host and port")
-	}
-	if strings.Split(u.Host, ":")[1] == "" {
-		return nil, errors.New("invalid connection string: missing port after port-separator colon")
+	hosts := strings.Split(u.Host, ",")
+	for _, host := range hosts {
+		if len(strings.Split(host, ":")) != 2 {
+			return nil, errors.New("invalid connection string: host url must have both host and port")
+		}
+		if strings.Split(host, ":")[1] == "" {
+			return nil, errors.New("invalid connection string: missing port after port-separator colon")
+		}
 	}
 
-	opts := []ClientOption{}
 	if apiKey != "" {
 		opts = append(opts, WithDgraphAPIKey(apiKey))
 	}
@@ -182,21 +596,87 @@ func Open(connStr string) (*Dgraph, error) {
 		opts = append(opts, WithBearerToken(bearerToken))
 	}
 
+	if (sslCert == "") != (sslKey == "") {
+		return nil, errors.New("invalid connection string: both sslcert and sslkey must be provided")
+	}
+	hasCertParams := sslRootCert != "" || sslCert != ""
 	if sslMode == "" {
 		sslMode = sslModeDisable
+		if hasCertParams || u.Scheme == httpsScheme {
+			sslMode = sslModeVerifyFull
+		}
+	}
+	if (u.Scheme == httpScheme && sslMode != sslModeDisable) ||
+		(u.Scheme == httpsScheme && sslMode == sslModeDisable) {
+		return nil, fmt.Errorf("invalid connection string: sslmode=%s cannot be used with %s://", sslMode, u.Scheme)
 	}
 	switch sslMode {
 	case sslModeDisable:
+		if hasCertParams {
+			return nil, fmt.Errorf("invalid connection string: certificate parameters cannot be used with sslmode=%s",
+				sslModeDisable)
+		}
 		opts = append(opts, WithGrpcOption(grpc.WithTransportCredentials(insecure.NewCredentials())))
 	case sslModeRequire:
-		opts = append(opts, WithSkipTLSVerify())
-	case sslModeVerifyCA:
-		opts = append(opts, WithSystemCertPool())
+		// As with libpq, the CA certificates are verified if provided.
+		if sslRootCert != "" {
+			opts = append(opts, WithCACertFile(sslRootCert), WithVerifyCAOnly())
+		} else {
+			opts = append(opts, WithSkipTLSVerify())
+		}
+	case sslModeVerifyCA, sslModeVerifyFull:
+		if sslRootCert != "" {
+			opts = append(opts, WithCACertFile(sslRootCert))
+		} else {
+			opts = append(opts, WithSystemCertPool())
+		}
+		if sslMode == sslModeVerifyCA {
+			opts = append(opts, WithVerifyCAOnly())
+		}
 	default:
-		return nil, fmt.Errorf("invalid SSL mode: %s (must be one of %s, %s, %s)",
-			sslMode, sslModeDisable, sslModeRequire, sslModeVerifyCA)
+		return nil, fmt.Errorf("invalid SSL mode: %s (must be one of %s, %s, %s, %s)",
+			sslMode, sslModeDisable, sslModeRequire, sslModeVerifyCA, sslModeVerifyFull)
+	}
+	if sslCert != "" {
+		opts = append(opts, WithClientCertificate(sslCert, sslKey))
+	}
+	if sslReload != "" {
+		if !hasCertParams {
+			return nil, errors.New("invalid connection string: sslreload requires sslrootcert or sslcert")
+		}
+		interval, err := time.ParseDuration(sslReload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sslreload interval: %w", err)
+		}
+		opts = append(opts, WithTLSReload(interval))
+	}
+	if serverNames != "" {
+		if sslMode == sslModeDisable {
+			return nil, fmt.Errorf("invalid connection string: sslservername cannot be used with sslmode=%s",
+				sslModeDisable)
+		}
+		names := strings.Split(serverNames, ",")
+		switch len(names) {
+		case 1:
+			opts = append(opts, WithServerName(names[0]))
+		case len(hosts):
+			for i, host := range hosts {
+				opts = append(opts, WithEndpointServerName(host, names[i]))
+			}
+		default:
+			return nil, errors.New("invalid connection string: sslservername must have one name or one for each host")
+		}
+	}
+	if lbPolicy != "" {
+		opts = append(opts, WithLoadBalancingPolicy(LoadBalancingPolicy(lbPolicy)))
 	}
 
+	transportOpts, err := parseTransportParams(params)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, transportOpts...)
+
 	if nsID != "" {
 		nsID, err := strconv.ParseUint(nsID, 10, 64)
 		if err != nil {
@@ -205,16 +685,106 @@ func Open(connStr string) (*Dgraph, error) {
 		opts = append(opts, WithNamespace(nsID))
 	}
 
+	cs := &connString{
+		hosts:          hosts,
+		opts:           opts,
+		hasAPIKey:      apiKey != "",
+		hasBearerToken: bearerToken != "",
+	}
 	if u.User != nil {
-		username := u.User.Username()
-		password, _ := u.User.Password()
-		if username == "" || password == "" {
-			return nil, errors.New("invalid connection string: both username and password must be provided")
+		cs.hasUser = true
+		cs.username = u.User.Username()
+		cs.password, _ = u.User.Password()
+	}
+	return cs, nil
+}
+
+// parseTransportParams returns the client options for the timeout, keepalive,
+// message size, compression and retry parameters of a connection string.
+func parseTransportParams(params url.Values) ([]ClientOption, error) {
+	parseDuration := func(param string) (time.Duration, error) {
+		d, err := time.ParseDuration(params.Get(param))
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %w", param, err)
+		}
+		if d <= 0 {
+			return 0, fmt.Errorf("invalid %s: must be positive", param)
+		}
+		return d, nil
+	}
+	parseInt := func(param string) (int, error) {
+		n, err := strconv.Atoi(params.Get(param))
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %w", param, err)
+		}
+		if n <= 0 {
+			return 0, fmt.Errorf("invalid %s: must be positive", param)
 		}
-		opts = append(opts, WithACLCreds(username, password))
+		return n, nil
 	}
 
-	return NewClient(u.Host, opts...)
+	var opts []ClientOption
+	if params.Has(timeoutParam) {
+		d, err := parseDuration(timeoutParam)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithRequestTimeout(d))
+	}
+	if params.Has(connectTimeoutParam) {
+		d, err := parseDuration(connectTimeoutParam)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithConnectTimeout(d))
+	}
+	if params.Has(keepaliveTimeoutParam) && !params.Has(keepaliveParam) {
+		return nil, fmt.Errorf("invalid connection string: %s requires %s",
+			keepaliveTimeoutParam, keepaliveParam)
+	}
+	if params.Has(keepaliveParam) {
+		interval, err := parseDuration(keepaliveParam)
+		if err != nil {
+			return nil, err
+		}
+		var timeout time.Duration
+		if params.Has(keepaliveTimeoutParam) {
+			if timeout, err = parseDuration(keepaliveTimeoutParam); err != nil {
+				return nil, err
+			}
+		}
+		opts = append(opts, WithKeepalive(interval, timeout))
+	}
+	if params.Has(maxRecvMsgSizeParam) {
+		n, err := parseInt(maxRecvMsgSizeParam)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithMaxRecvMsgSize(n))
+	}
+	if params.Has(maxSendMsgSizeParam) {
+		n, err := parseInt(maxSendMsgSizeParam)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithMaxSendMsgSize(n))
+	}
+	switch compression := params.Get(compressionParam); compression {
+	case "", compressionNone:
+	case compressionGzip:
+		opts = append(opts, WithGzipCompression())
+	default:
+		return nil, fmt.Errorf("invalid compression: %s (must be one of %s, %s)",
+			compression, compressionNone, compressionGzip)
+	}
+	if params.Has(maxRetriesParam) {
+		n, err := parseInt(maxRetriesParam)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithRetryPolicy(RetryPolicy{MaxRetries: n}))
+	}
+	return opts, nil
 }
 
 // NewClient creates a new Dgraph client for a single endpoint.
@@ -236,29 +806,71 @@ func NewRoundRobinClient(endpoints []string, opts ...ClientOption) (*Dgraph, err
 		}
 	}
 
-	conns := make([]*grpc.ClientConn, len(endpoints))
-	dc := make([]api.DgraphClient, len(endpoints))
-	for i, endpoint := range endpoints {
-		conn, err := grpc.NewClient(endpoint, co.gopts...)
+	var reloader *certReloader
+	if co.reloadInterval > 0 {
+		if co.caFile == "" && co.certFile == "" {
+			return nil, errors.New("TLS reload requires a CA certificate file or a client certificate")
+		}
+		r, err := newCertReloader(co.caFile, co.certFile, co.keyFile, co.reloadInterval)
 		if err != nil {
+			return nil, err
+		}
+		reloader = r
+	}
+
+	d := &Dgraph{
+		conns:     make([]*grpc.ClientConn, 0, len(endpoints)),
+		dc:        make([]api.DgraphClient, 0, len(endpoints)),
+		endpoints: slices.Clone(endpoints),
+		lbPolicy:  co.lbPolicy,
+		httpAuth:  co.httpAuth,
+		cache:     co.cache,
+		hedger:    co.hedger,
+
+		interceptors: co.interceptors,
+	}
+	for _, endpoint := range endpoints {
+		if co.useHTTP {
+			hc, err := newHTTPClient(endpoint, co, reloader)
+			if err != nil {
+				return nil, err
+			}
+			d.dc = append(d.dc, withBreaker(withLimits(hc, co), endpoint, co))
+			continue
+		}
+
+		gopts := co.gopts
+		if creds := co.transportCredentials(endpoint, reloader); creds != nil {
+			gopts = append(slices.Clip(gopts), grpc.WithTransportCredentials(creds))
+		}
+		conn, err := grpc.NewClient(endpoint, gopts...)
+		if err != nil {
+			d.Close()
 			return nil, fmt.Errorf("failed to connect to endpoint [%s]: %w", endpoint, err)
 		}
-		conns[i] = conn
-		dc[i] = api.NewDgraphClient(conn)
+		d.conns = append(d.conns, conn)
+		d.dc = append(d.dc, withBreaker(withLimits(api.NewDgraphClient(conn), co), endpoint, co))
 	}
 
-	d := &Dgraph{dc: dc}
-	if co.username != "" && co.password != "" {
-		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
-		defer cancel()
+	// The login and the version check made at startup use the timeout of logins,
+	// or else the timeout of requests, so that they don't block forever.
+	timeout := requestTimeout
+	if t := co.timeouts[RequestLogin]; t > 0 {
+		timeout = t
+	} else if co.requestTimeout > 0 {
+		timeout = co.requestTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
+	if co.username != "" && co.password != "" {
 		if err := d.login(ctx, co.username, co.password, co.namespace); err != nil {
 			d.Close()
 			return nil, fmt.Errorf("failed to sign in user: %w", err)
 		}
 	}
 
-	if _, err := dc[0].CheckVersion(context.Background(), &api.Check{}); err != nil {
+	if _, err := d.dc[0].CheckVersion(ctx, &api.Check{}); err != nil {
 		d.Close()
 		return nil, fmt.Errorf("failed to ping: %w", err)
 	}
@@ -266,13 +878,71 @@ func NewRoundRobinClient(endpoints []string, opts ...ClientOption) (*Dgraph, err
 	return d, nil
 }
 
+// transportCredentials returns the TLS credentials for the given endpoint, or nil
+// if TLS has not been configured using the TLS client options.
+func (co *clientOptions) transportCredentials(endpoint string, r *certReloader) credentials.TransportCredentials {
+	cfg := co.endpointTLSConfig(endpoint, r)
+	if cfg == nil {
+		return nil
+	}
+	if r != nil {
+		return newReloadingCreds(cfg, r)
+	}
+	return credentials.NewTLS(cfg)
+}
+
+// verifyChain verifies the certificate chain of a connection against the given
+// roots, or the system roots if nil, but not the server hostname.
+func verifyChain(cs tls.ConnectionState, roots *x509.CertPool) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("no certificate presented by the server")
+	}
+	opts := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+// endpointTLSConfig returns the TLS configuration for the given endpoint, or nil
+// if TLS has not been configured using the TLS client options. If r is not nil, it
+// provides the CA certificates verified by WithVerifyCAOnly.
+func (co *clientOptions) endpointTLSConfig(endpoint string, r *certReloader) *tls.Config {
+	if co.tlsConfig == nil {
+		return nil
+	}
+
+	cfg := co.tlsConfig
+	if serverName, ok := co.serverNames[endpoint]; ok {
+		cfg = cfg.Clone()
+		cfg.ServerName = serverName
+	}
+	if co.verifyCAOnly && !cfg.InsecureSkipVerify {
+		cfg = cfg.Clone()
+		roots := cfg.RootCAs
+		// The standard verification also checks the hostname, so it is replaced.
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			if r != nil {
+				if pool := r.rootCAs(); pool != nil {
+					return verifyChain(cs, pool)
+				}
+			}
+			return verifyChain(cs, roots)
+		}
+	}
+	return cfg
+}
+
 // GetAPIClients returns the api.DgraphClient that is useful for advanced
 // cases when grpc API that are not exposed in dgo needs to be used.
 func (d *Dgraph) GetAPIClients() []api.DgraphClient {
 	return d.dc
 }
 
-// Close shutdown down all the connections to the Dgraph Cluster.
+// Close shutdown down all the connections to the Dgraph Cluster. It is a no-op for
+// the handles returned by ForNamespace.
 func (d *Dgraph) Close() {
 	for _, conn := range d.conns {
 		_ = conn.Close()
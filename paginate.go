@@ -0,0 +1,106 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"strconv"
+
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+const (
+	pageFirstVar = "$first"
+	pageAfterVar = "$after"
+)
+
+// QueryPages runs the query page by page in a single read-only transaction, so
+// every page is read from the same snapshot of the graph. See Txn.QueryPages.
+func (d *Dgraph) QueryPages(ctx context.Context, q string, vars map[string]string,
+	block string, pageSize int) iter.Seq2[*api.Response, error] {
+
+	return d.NewReadOnlyTxn().QueryPages(ctx, q, vars, block, pageSize)
+}
+
+// QueryPages returns an iterator that repeatedly runs the query in this transaction,
+// yielding one response per page until the named block returns fewer than pageSize
+// results. As all the pages are queried in the same transaction, they are read at
+// the same start timestamp.
+//
+// The query must declare the variables $first and $after, use them to paginate the
+// given block, and request the uid of each node in that block, e.g.
+//
+//	query q($first: int, $after: string) {
+//		people(func: has(name), first: $first, after: $after) {
+//			uid
+//			name
+//		}
+//	}
+//
+// The uid of the last node on a page is passed as $after for the next one.
+func (txn *Txn) QueryPages(ctx context.Context, q string, vars map[string]string,
+	block string, pageSize int) iter.Seq2[*api.Response, error] {
+
+	return func(yield func(*api.Response, error) bool) {
+		if pageSize <= 0 {
+			yield(nil, fmt.Errorf("invalid page size: %d", pageSize))
+			return
+		}
+
+		after := "0x0"
+		for {
+			pageVars := make(map[string]string, len(vars)+2)
+			maps.Copy(pageVars, vars)
+			pageVars[pageFirstVar] = strconv.Itoa(pageSize)
+			pageVars[pageAfterVar] = after
+
+			resp, err := txn.QueryWithVars(ctx, q, pageVars)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			count, lastUid, err := pageCursor(resp, block)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if count == 0 {
+				return
+			}
+			if !yield(resp, nil) || count < pageSize {
+				return
+			}
+			after = lastUid
+		}
+	}
+}
+
+// pageCursor returns the number of nodes in the given block of the response
+// along with the uid of the last one.
+func pageCursor(resp *api.Response, block string) (int, string, error) {
+	type node struct {
+		Uid string `json:"uid"`
+	}
+
+	var count int
+	var lastUid string
+	for n, err := range IterBlock[node](resp, block) {
+		if err != nil {
+			return 0, "", err
+		}
+		if n.Uid == "" {
+			return 0, "", errors.New("paginated query must request uid for every node in the block")
+		}
+		count++
+		lastUid = n.Uid
+	}
+	return count, lastUid, nil
+}
@@ -0,0 +1,115 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgo/v250"
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+func TestQueryPagesOffline(t *testing.T) {
+	const total, startTs = 7, 42
+
+	var reqs []*api.Request
+	fake := &fakeDgraphClient{query: func(_ context.Context, req *api.Request) (*api.Response, error) {
+		reqs = append(reqs, req)
+		first, err := strconv.Atoi(req.Vars["$first"])
+		require.NoError(t, err)
+		after, err := strconv.ParseUint(strings.TrimPrefix(req.Vars["$after"], "0x"), 16, 64)
+		require.NoError(t, err)
+
+		var nodes []string
+		for uid := after + 1; uid <= total && len(nodes) < first; uid++ {
+			nodes = append(nodes, fmt.Sprintf(`{"uid": "%#x"}`, uid))
+		}
+		return &api.Response{
+			Json: []byte(`{"q": [` + strings.Join(nodes, ",") + `]}`),
+			Txn:  &api.TxnContext{StartTs: startTs},
+		}, nil
+	}}
+	dg := dgo.NewDgraphClient(fake)
+
+	var pages int
+	for resp, err := range dg.QueryPages(context.Background(), "query", map[string]string{"$name": "x"}, "q", 3) {
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		pages++
+	}
+	require.Equal(t, 3, pages)
+	require.Len(t, reqs, 3)
+
+	require.Equal(t, "0x0", reqs[0].Vars["$after"])
+	require.Equal(t, "0x3", reqs[1].Vars["$after"])
+	require.Equal(t, "0x6", reqs[2].Vars["$after"])
+	require.Zero(t, reqs[0].StartTs)
+	for _, req := range reqs {
+		require.True(t, req.ReadOnly)
+		require.Equal(t, "x", req.Vars["$name"])
+		require.Equal(t, "3", req.Vars["$first"])
+	}
+	for _, req := range reqs[1:] {
+		require.Equal(t, uint64(startTs), req.StartTs)
+	}
+
+	for _, err := range dg.QueryPages(context.Background(), "query", nil, "q", 0) {
+		require.ErrorContains(t, err, "invalid page size")
+	}
+}
+
+func TestQueryPagesMissingUid(t *testing.T) {
+	fake := &fakeDgraphClient{query: func(_ context.Context, _ *api.Request) (*api.Response, error) {
+		return &api.Response{Json: []byte(`{"q": [{"name": "Alice"}]}`)}, nil
+	}}
+	dg := dgo.NewDgraphClient(fake)
+
+	for _, err := range dg.QueryPages(context.Background(), "query", nil, "q", 10) {
+		require.ErrorContains(t, err, "must request uid")
+	}
+}
+
+func TestQueryPages(t *testing.T) {
+	dg, cancel := getDgraphClient()
+	defer cancel()
+
+	ctx := context.Background()
+	require.NoError(t, dg.DropAll(ctx))
+	require.NoError(t, dg.SetSchema(ctx, `name: string @index(exact) .`))
+
+	var nquads strings.Builder
+	for i := range 25 {
+		fmt.Fprintf(&nquads, "_:n%d <name> \"person%d\" .\n", i, i)
+	}
+	_, err := dg.RunDQL(ctx, `{ set { `+nquads.String()+` } }`)
+	require.NoError(t, err)
+
+	q := `query q($first: int, $after: string) {
+		q(func: has(name), first: $first, after: $after) {
+			uid
+			name
+		}
+	}`
+
+	type person struct {
+		Name string `json:"name"`
+	}
+	seen := make(map[string]struct{})
+	for resp, err := range dg.QueryPages(ctx, q, nil, "q", 10) {
+		require.NoError(t, err)
+		for p, err := range dgo.IterBlock[person](resp, "q") {
+			require.NoError(t, err)
+			seen[p.Name] = struct{}{}
+		}
+	}
+	require.Len(t, seen, 25)
+}
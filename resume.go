@@ -0,0 +1,110 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+const txnStateVersion = 1
+
+// ErrInvalidTxnToken is returned by ResumeTxn when the token cannot be decoded.
+var ErrInvalidTxnToken = errors.New("invalid transaction token")
+
+// txnState is the serialized form of a transaction used by Handoff and ResumeTxn.
+type txnState struct {
+	Version    int      `json:"v"`
+	StartTs    uint64   `json:"start_ts"`
+	Hash       string   `json:"hash,omitempty"`
+	Keys       []string `json:"keys,omitempty"`
+	Preds      []string `json:"preds,omitempty"`
+	Mutated    bool     `json:"mutated,omitempty"`
+	ReadOnly   bool     `json:"read_only,omitempty"`
+	BestEffort bool     `json:"best_effort,omitempty"`
+	Endpoint   string   `json:"endpoint,omitempty"`
+}
+
+// Handoff serializes the state of the transaction into a token that can be passed
+// to ResumeTxn, possibly in another process, to continue the same transaction.
+// This allows multiple services to contribute mutations to one atomic commit.
+//
+// Handoff waits for in-flight requests to complete. The transaction is handed off
+// by the call: all subsequent operations on it return ErrFinished, and Discard is
+// a no-op, so a deferred Discard doesn't abort the transaction in the process that
+// resumes it. The token is not encrypted and should be passed over trusted channels.
+func (txn *Txn) Handoff() (string, error) {
+	txn.reqMu.Lock()
+	defer txn.reqMu.Unlock()
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+
+	if txn.finished {
+		return "", ErrFinished
+	}
+
+	state := txnState{
+		Version:    txnStateVersion,
+		StartTs:    txn.context.StartTs,
+		Hash:       txn.context.Hash,
+		Mutated:    txn.mutated,
+		ReadOnly:   txn.readOnly,
+		BestEffort: txn.bestEffort,
+		Endpoint:   txn.endpoint,
+	}
+	for key := range txn.keys {
+		state.Keys = append(state.Keys, key)
+	}
+	for pred := range txn.preds {
+		state.Preds = append(state.Preds, pred)
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling transaction state: %w", err)
+	}
+	txn.finished = true
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// ResumeTxn continues a transaction from a token created by Txn.Handoff. If the
+// endpoint that the handed off transaction was pinned to is one of the endpoints of
+// this client, the resumed transaction is pinned to it as well.
+func (d *Dgraph) ResumeTxn(token string) (*Txn, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTxnToken, err)
+	}
+	var state txnState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTxnToken, err)
+	}
+	if state.Version != txnStateVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidTxnToken, state.Version)
+	}
+
+	i := slices.Index(d.endpoints, state.Endpoint)
+	if state.Endpoint == "" || i < 0 {
+		i = d.anyClientIndex()
+	}
+
+	txn := d.newTxn(i)
+	txn.context.StartTs = state.StartTs
+	txn.context.Hash = state.Hash
+	txn.mutated = state.Mutated
+	txn.readOnly = state.ReadOnly
+	txn.bestEffort = state.BestEffort
+	for _, key := range state.Keys {
+		txn.keys[key] = struct{}{}
+	}
+	for _, pred := range state.Preds {
+		txn.preds[pred] = struct{}{}
+	}
+	return txn, nil
+}
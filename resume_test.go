@@ -0,0 +1,112 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgo/v250"
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+func TestResumeTxnOffline(t *testing.T) {
+	newFake := func(key string, commits *[]*api.TxnContext) *fakeDgraphClient {
+		return &fakeDgraphClient{
+			query: func(_ context.Context, req *api.Request) (*api.Response, error) {
+				startTs := req.StartTs
+				if startTs == 0 {
+					startTs = 10
+				}
+				return &api.Response{Txn: &api.TxnContext{
+					StartTs: startTs,
+					Hash:    "hash",
+					Keys:    []string{key},
+					Preds:   []string{key + "-pred"},
+				}}, nil
+			},
+			commitOrAbort: func(_ context.Context, tc *api.TxnContext) (*api.TxnContext, error) {
+				*commits = append(*commits, tc)
+				return tc, nil
+			},
+		}
+	}
+
+	ctx := context.Background()
+	var commitsA, commitsB []*api.TxnContext
+	dgA := dgo.NewDgraphClient(newFake("a", &commitsA))
+	dgB := dgo.NewDgraphClient(newFake("b", &commitsB))
+
+	txnA := dgA.NewTxn()
+	_, err := txnA.Mutate(ctx, &api.Mutation{SetNquads: []byte(`_:a <name> "A" .`)})
+	require.NoError(t, err)
+
+	token, err := txnA.Handoff()
+	require.NoError(t, err)
+
+	// The handed off transaction must not be aborted locally.
+	require.NoError(t, txnA.Discard(ctx))
+	require.Empty(t, commitsA)
+	_, err = txnA.Query(ctx, `{ q(func: uid(1)) { uid } }`)
+	require.ErrorIs(t, err, dgo.ErrFinished)
+	_, err = txnA.Handoff()
+	require.ErrorIs(t, err, dgo.ErrFinished)
+
+	txnB, err := dgB.ResumeTxn(token)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), txnB.StartTs())
+	_, err = txnB.Mutate(ctx, &api.Mutation{SetNquads: []byte(`_:b <name> "B" .`)})
+	require.NoError(t, err)
+	require.NoError(t, txnB.Commit(ctx))
+
+	require.Len(t, commitsB, 1)
+	require.Equal(t, uint64(10), commitsB[0].StartTs)
+	require.Equal(t, "hash", commitsB[0].Hash)
+	require.ElementsMatch(t, []string{"a", "b"}, commitsB[0].Keys)
+	require.ElementsMatch(t, []string{"a-pred", "b-pred"}, commitsB[0].Preds)
+}
+
+func TestResumeTxnInvalidToken(t *testing.T) {
+	dg := dgo.NewDgraphClient(&fakeDgraphClient{})
+
+	_, err := dg.ResumeTxn("not a token!")
+	require.ErrorIs(t, err, dgo.ErrInvalidTxnToken)
+
+	// base64url of `{"v":99}`
+	_, err = dg.ResumeTxn("eyJ2Ijo5OX0")
+	require.ErrorIs(t, err, dgo.ErrInvalidTxnToken)
+	require.ErrorContains(t, err, "unsupported version 99")
+}
+
+func TestResumeTxn(t *testing.T) {
+	dgA, cancelA := getDgraphClient()
+	defer cancelA()
+	dgB, cancelB := getDgraphClient()
+	defer cancelB()
+
+	ctx := context.Background()
+	require.NoError(t, dgA.DropAll(ctx))
+	require.NoError(t, dgA.SetSchema(ctx, `name: string @index(exact) .`))
+
+	txnA := dgA.NewTxn()
+	defer func() { require.NoError(t, txnA.Discard(ctx)) }()
+	_, err := txnA.Mutate(ctx, &api.Mutation{SetNquads: []byte(`_:a <name> "Alice" .`)})
+	require.NoError(t, err)
+	token, err := txnA.Handoff()
+	require.NoError(t, err)
+
+	txnB, err := dgB.ResumeTxn(token)
+	require.NoError(t, err)
+	_, err = txnB.Mutate(ctx, &api.Mutation{SetNquads: []byte(`_:b <name> "Bob" .`)})
+	require.NoError(t, err)
+	require.NoError(t, txnB.Commit(ctx))
+
+	resp, err := dgA.NewReadOnlyTxn().Query(ctx, `{ q(func: has(name), orderasc: name) { name } }`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"q": [{"name": "Alice"}, {"name": "Bob"}]}`, string(resp.Json))
+}
@@ -0,0 +1,133 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+// IterBlock returns an iterator over the elements of the named query block in the
+// JSON response. Elements are decoded one at a time into T using a token-level
+// decoder, so the full result is never unmarshalled into memory at once. It works
+// with responses from both Txn queries and RunDQL.
+//
+// For example, given a response to `{ people(func: has(name)) { name } }`:
+//
+//	for p, err := range dgo.IterBlock[Person](resp, "people") {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// If the block is absent or null in the response, the iterator yields nothing.
+func IterBlock[T any](resp *api.Response, block string) iter.Seq2[T, error] {
+	return DecodeBlock[T](bytes.NewReader(resp.GetJson()), block)
+}
+
+// DecodeBlock is like IterBlock, but reads the JSON response from r.
+func DecodeBlock[T any](r io.Reader, block string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		dec := json.NewDecoder(r)
+		found, err := seekBlock(dec, block)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		if !found {
+			return
+		}
+
+		for dec.More() {
+			var elem T
+			if err := dec.Decode(&elem); err != nil {
+				yield(zero, fmt.Errorf("error decoding element of block %q: %w", block, err))
+				return
+			}
+			if !yield(elem, nil) {
+				return
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			yield(zero, fmt.Errorf("error reading end of block %q: %w", block, err))
+		}
+	}
+}
+
+// seekBlock advances dec to the first element of the array stored under the given key
+// of the top level JSON object. It returns false if the key is absent or null.
+func seekBlock(dec *json.Decoder, block string) (bool, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return false, fmt.Errorf("error reading response: %w", err)
+	}
+	if tok == nil {
+		return false, nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return false, errors.New("response is not a JSON object")
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return false, fmt.Errorf("error reading response: %w", err)
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return false, fmt.Errorf("unexpected token %v in response", tok)
+		}
+		if key != block {
+			if err := skipValue(dec); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		tok, err = dec.Token()
+		if err != nil {
+			return false, fmt.Errorf("error reading block %q: %w", block, err)
+		}
+		if tok == nil {
+			return false, nil
+		}
+		if d, ok := tok.(json.Delim); !ok || d != '[' {
+			return false, fmt.Errorf("block %q is not a JSON array", block)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// skipValue consumes the next JSON value from dec without decoding it.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("error reading response: %w", err)
+		}
+		if d, ok := tok.(json.Delim); ok {
+			switch d {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,77 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgo/v250"
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+func TestIterBlock(t *testing.T) {
+	type Person struct {
+		Name    string   `json:"name"`
+		Friends []Person `json:"friend"`
+	}
+
+	resp := &api.Response{Json: []byte(`{
+		"other": [{"name": "x", "nested": {"a": [1, 2, {"b": null}]}}],
+		"count": 3,
+		"people": [
+			{"name": "Alice", "friend": [{"name": "Bob"}]},
+			{"name": "Charlie"}
+		],
+		"empty": null
+	}`)}
+
+	var names []string
+	for p, err := range dgo.IterBlock[Person](resp, "people") {
+		require.NoError(t, err)
+		names = append(names, p.Name)
+	}
+	require.Equal(t, []string{"Alice", "Charlie"}, names)
+
+	for range dgo.IterBlock[Person](resp, "missing") {
+		t.Fatal("no elements expected for a missing block")
+	}
+	for range dgo.IterBlock[Person](resp, "empty") {
+		t.Fatal("no elements expected for a null block")
+	}
+
+	// Stop iterating early.
+	for p, err := range dgo.IterBlock[Person](resp, "people") {
+		require.NoError(t, err)
+		require.Equal(t, "Alice", p.Name)
+		break
+	}
+
+	for _, err := range dgo.IterBlock[Person](resp, "count") {
+		require.ErrorContains(t, err, `block "count" is not a JSON array`)
+	}
+}
+
+func TestDecodeBlockMalformed(t *testing.T) {
+	var got []map[string]any
+	var gotErr error
+	r := strings.NewReader(`{"q": [{"uid": "0x1"}, {"uid": `)
+	for elem, err := range dgo.DecodeBlock[map[string]any](r, "q") {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		got = append(got, elem)
+	}
+	require.Len(t, got, 1)
+	require.ErrorContains(t, gotErr, `error decoding element of block "q"`)
+
+	for _, err := range dgo.DecodeBlock[int](strings.NewReader(`[1, 2]`), "q") {
+		require.ErrorContains(t, err, "response is not a JSON object")
+	}
+}
@@ -0,0 +1,357 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+const (
+	tenantNamePred      = "dgo.tenant.name"
+	tenantNamespacePred = "dgo.tenant.namespace"
+
+	// defaultGrootPassword is the password that Dgraph gives to the groot user of
+	// new namespaces, and that CreateTenant replaces.
+	defaultGrootPassword = "password"
+
+	tenantSchema = tenantNamePred + `: string @index(exact) @upsert .
+` + tenantNamespacePred + `: int .`
+
+	tenantFields = `{
+		name: ` + tenantNamePred + `
+		namespace: ` + tenantNamespacePred + `
+	}`
+)
+
+var (
+	// ErrTenantNotFound is returned when no tenant with the given name is registered.
+	ErrTenantNotFound = errors.New("tenant not found")
+	// ErrTenantExists is returned when creating a tenant whose name is already registered.
+	ErrTenantExists = errors.New("tenant already exists")
+)
+
+// Tenant is a named namespace registered with a TenantManager.
+type Tenant struct {
+	Name      string `json:"name"`
+	Namespace uint64 `json:"namespace"`
+}
+
+// TenantSpec describes the initial state of the namespace of a new tenant.
+type TenantSpec struct {
+	// Schema is applied to the namespace once it is created.
+	Schema string
+	// Seed holds mutations that are run in a single transaction once the schema
+	// has been applied.
+	Seed []*api.Mutation
+}
+
+type tenantOptions struct {
+	username string
+	password string
+}
+
+// TenantOption is a function that modifies the tenant manager options.
+type TenantOption func(*tenantOptions) error
+
+// WithTenantCredentials sets the credentials used to log into the namespaces of
+// the tenants, which are required. When a tenant is created, the password of the
+// groot user of its namespace is changed from the default password to the given
+// password, and if username is not groot, the user is created as a guardian of
+// the namespace.
+func WithTenantCredentials(username, password string) TenantOption {
+	return func(o *tenantOptions) error {
+		if username == "" || password == "" {
+			return errors.New("both username and password must be provided")
+		}
+		o.username = username
+		o.password = password
+		return nil
+	}
+}
+
+// TenantManager manages the lifecycle of tenants, each of which is stored in its
+// own namespace. The mapping from tenant names to namespaces is stored in the galaxy
+// namespace using the dgo.tenant.name and dgo.tenant.namespace predicates, so the
+// client must be logged into the galaxy namespace as a guardian.
+type TenantManager struct {
+	dg   *Dgraph
+	opts tenantOptions
+
+	mu          sync.Mutex
+	schemaReady bool // whether the schema of the registry has been applied
+}
+
+// NewTenantManager creates a TenantManager that uses the given client. The
+// credentials of the tenants must be set using WithTenantCredentials.
+func NewTenantManager(dg *Dgraph, opts ...TenantOption) (*TenantManager, error) {
+	m := &TenantManager{dg: dg}
+	for _, opt := range opts {
+		if err := opt(&m.opts); err != nil {
+			return nil, err
+		}
+	}
+	if m.opts.username == "" {
+		return nil, errors.New("tenant credentials must be provided using WithTenantCredentials")
+	}
+	return m, nil
+}
+
+// CreateTenant creates a namespace for a new tenant, registers it under the given
+// name and applies the spec to it, if not nil. If any of the steps fails, the
+// namespace is dropped and the tenant is unregistered again.
+//
+// The groot user of the new namespace is logged into with the default password
+// of Dgraph, so CreateTenant fails if the server gives new namespaces a different
+// password.
+func (m *TenantManager) CreateTenant(ctx context.Context, name string, spec *TenantSpec) (*Tenant, error) {
+	if name == "" {
+		return nil, errors.New("tenant name cannot be empty")
+	}
+	if _, err := m.Tenant(ctx, name); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrTenantExists, name)
+	} else if !errors.Is(err, ErrTenantNotFound) {
+		return nil, err
+	}
+	if err := m.setupRegistry(ctx); err != nil {
+		return nil, err
+	}
+
+	nsID, err := m.dg.CreateNamespace(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error creating namespace for tenant %s: %w", name, err)
+	}
+	t := &Tenant{Name: name, Namespace: nsID}
+	if err := m.register(ctx, t); err != nil {
+		_ = m.dg.DropNamespace(context.WithoutCancel(ctx), nsID)
+		return nil, err
+	}
+	if err := m.secure(ctx, t); err != nil {
+		_ = m.drop(context.WithoutCancel(ctx), t)
+		return nil, fmt.Errorf("error securing tenant %s: %w", name, err)
+	}
+	if spec != nil {
+		if err := m.setup(ctx, t, spec); err != nil {
+			_ = m.drop(context.WithoutCancel(ctx), t)
+			return nil, fmt.Errorf("error setting up tenant %s: %w", name, err)
+		}
+	}
+	return t, nil
+}
+
+// setupRegistry applies the schema of the registry, unless it has already been.
+func (m *TenantManager) setupRegistry(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.schemaReady {
+		return nil
+	}
+	if err := m.dg.SetSchema(ctx, tenantSchema); err != nil {
+		return fmt.Errorf("error setting up tenant registry: %w", err)
+	}
+	m.schemaReady = true
+	return nil
+}
+
+// secure replaces the default password of the groot user of the namespace of the
+// tenant with the password of the tenant credentials, creating the user of the
+// credentials as a guardian if it is not groot.
+func (m *TenantManager) secure(ctx context.Context, t *Tenant) error {
+	dg, err := m.dg.ForNamespace(ctx, t.Namespace, "groot", defaultGrootPassword)
+	if err != nil {
+		return err
+	}
+	query := `{
+		groot as var(func: eq(dgraph.xid, "groot")) @filter(type(dgraph.type.User))`
+	set := []map[string]any{{"uid": "uid(groot)", "dgraph.password": m.opts.password}}
+	if m.opts.username != "groot" {
+		query += `
+		guardians as var(func: eq(dgraph.xid, "guardians")) @filter(type(dgraph.type.Group))`
+		set = append(set, map[string]any{
+			"uid":               "_:user",
+			"dgraph.type":       "dgraph.type.User",
+			"dgraph.xid":        m.opts.username,
+			"dgraph.password":   m.opts.password,
+			"dgraph.user.group": map[string]string{"uid": "uid(guardians)"},
+		})
+	}
+	setJSON, err := json.Marshal(set)
+	if err != nil {
+		return err
+	}
+	req := &api.Request{
+		Query:     query + "\n\t}",
+		Mutations: []*api.Mutation{{SetJson: setJSON}},
+		CommitNow: true,
+	}
+	_, err = dg.NewTxn().Do(ctx, req)
+	return err
+}
+
+// tenantVarQuery returns a query setting the variable t to the tenant with the
+// given name. The name is inlined rather than passed as a variable, as upserts
+// with variables are not supported over HTTP. DQL unquotes strings like Go.
+func tenantVarQuery(name string) string {
+	return `{
+		t as var(func: eq(` + tenantNamePred + `, ` + strconv.Quote(name) + `))
+	}`
+}
+
+// register records the tenant in the registry, unless a tenant with the same
+// name has been registered concurrently.
+func (m *TenantManager) register(ctx context.Context, t *Tenant) error {
+	setJSON, err := json.Marshal(map[string]any{
+		"uid":               "_:tenant",
+		tenantNamePred:      t.Name,
+		tenantNamespacePred: t.Namespace,
+	})
+	if err != nil {
+		return err
+	}
+	req := &api.Request{
+		Query:     tenantVarQuery(t.Name),
+		Mutations: []*api.Mutation{{SetJson: setJSON, Cond: `@if(eq(len(t), 0))`}},
+		CommitNow: true,
+	}
+	resp, err := m.dg.NewTxn().Do(ctx, req)
+	if err != nil {
+		return fmt.Errorf("error registering tenant %s: %w", t.Name, err)
+	}
+	if _, ok := resp.Uids["tenant"]; !ok {
+		return fmt.Errorf("%w: %s", ErrTenantExists, t.Name)
+	}
+	return nil
+}
+
+func (m *TenantManager) setup(ctx context.Context, t *Tenant, spec *TenantSpec) error {
+	dg, err := m.Connect(ctx, t)
+	if err != nil {
+		return err
+	}
+	if spec.Schema != "" {
+		if err := dg.SetSchema(ctx, spec.Schema); err != nil {
+			return err
+		}
+	}
+	if len(spec.Seed) > 0 {
+		if _, err := dg.NewTxn().Do(ctx, &api.Request{Mutations: spec.Seed, CommitNow: true}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CloneTenant creates a tenant with the given name whose namespace is a copy of the
+// namespace of an existing tenant, e.g. to create a sandbox from a template tenant.
+// See CopyNamespace. If copying fails, the new tenant is dropped again.
+func (m *TenantManager) CloneTenant(ctx context.Context, from, name string, batchSize int) (*Tenant, error) {
+	src, err := m.Tenant(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	srcDg, err := m.Connect(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := m.CreateTenant(ctx, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	dstDg, err := m.Connect(ctx, t)
+	if err == nil {
+		err = CopyNamespace(ctx, srcDg, dstDg, batchSize)
+	}
+	if err != nil {
+		_ = m.drop(context.WithoutCancel(ctx), t)
+		return nil, fmt.Errorf("error cloning tenant %s into %s: %w", from, name, err)
+	}
+	return t, nil
+}
+
+// Tenant returns the tenant registered under the given name, or ErrTenantNotFound.
+func (m *TenantManager) Tenant(ctx context.Context, name string) (*Tenant, error) {
+	q := `query q($name: string) {
+		t(func: eq(` + tenantNamePred + `, $name)) ` + tenantFields + `
+	}`
+	resp, err := m.dg.NewReadOnlyTxn().QueryWithVars(ctx, q, map[string]string{"$name": name})
+	if err != nil {
+		return nil, err
+	}
+	for t, err := range IterBlock[*Tenant](resp, "t") {
+		return t, err
+	}
+	return nil, fmt.Errorf("%w: %s", ErrTenantNotFound, name)
+}
+
+// ListTenants returns all the registered tenants, ordered by name.
+func (m *TenantManager) ListTenants(ctx context.Context) ([]*Tenant, error) {
+	q := `{
+		t(func: has(` + tenantNamePred + `), orderasc: ` + tenantNamePred + `) ` + tenantFields + `
+	}`
+	resp, err := m.dg.NewReadOnlyTxn().Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	var tenants []*Tenant
+	for t, err := range IterBlock[*Tenant](resp, "t") {
+		if err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, t)
+	}
+	return tenants, nil
+}
+
+// Connect returns a handle for the namespace of the tenant, see Dgraph.ForNamespace.
+func (m *TenantManager) Connect(ctx context.Context, t *Tenant) (*Dgraph, error) {
+	dg, err := m.dg.ForNamespace(ctx, t.Namespace, m.opts.username, m.opts.password)
+	if err != nil {
+		return nil, fmt.Errorf("error logging into namespace of tenant %s: %w", t.Name, err)
+	}
+	return dg, nil
+}
+
+// DropTenant drops the namespace of the tenant with the given name, along with
+// all of its data, and removes the tenant from the registry.
+func (m *TenantManager) DropTenant(ctx context.Context, name string) error {
+	t, err := m.Tenant(ctx, name)
+	if err != nil {
+		return err
+	}
+	return m.drop(ctx, t)
+}
+
+// drop unregisters the tenant and then drops its namespace. If dropping the
+// namespace fails, the tenant is registered again so that dropping it can be
+// retried.
+func (m *TenantManager) drop(ctx context.Context, t *Tenant) error {
+	req := &api.Request{
+		Query: tenantVarQuery(t.Name),
+		Mutations: []*api.Mutation{{DelNquads: []byte(`
+			uid(t) <` + tenantNamePred + `> * .
+			uid(t) <` + tenantNamespacePred + `> * .
+		`)}},
+		CommitNow: true,
+	}
+	if _, err := m.dg.NewTxn().Do(ctx, req); err != nil {
+		return fmt.Errorf("error unregistering tenant %s: %w", t.Name, err)
+	}
+	if err := m.dg.DropNamespace(ctx, t.Namespace); err != nil {
+		err = fmt.Errorf("error dropping namespace of tenant %s: %w", t.Name, err)
+		if rerr := m.register(context.WithoutCancel(ctx), t); rerr != nil {
+			return errors.Join(err, rerr)
+		}
+		return err
+	}
+	return nil
+}
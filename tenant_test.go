@@ -0,0 +1,335 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dgraph-io/dgo/v250"
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+func TestTenantManagerOffline(t *testing.T) {
+	var vars []map[string]string
+	dg := dgo.NewDgraphClient(&fakeDgraphClient{
+		query: func(_ context.Context, req *api.Request) (*api.Response, error) {
+			vars = append(vars, req.Vars)
+			if req.Vars["$name"] == "acme" || req.Vars == nil {
+				return &api.Response{Json: []byte(`{"t": [
+					{"name": "acme", "namespace": 3},
+					{"name": "globex", "namespace": 5}
+				]}`)}, nil
+			}
+			return &api.Response{Json: []byte(`{"t": []}`)}, nil
+		},
+	})
+	m, err := dgo.NewTenantManager(dg, dgo.WithTenantCredentials("groot", "tenantpass"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	tenant, err := m.Tenant(ctx, "acme")
+	require.NoError(t, err)
+	require.Equal(t, &dgo.Tenant{Name: "acme", Namespace: 3}, tenant)
+
+	_, err = m.Tenant(ctx, "initech")
+	require.ErrorIs(t, err, dgo.ErrTenantNotFound)
+	require.ErrorContains(t, err, "initech")
+
+	tenants, err := m.ListTenants(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []*dgo.Tenant{{Name: "acme", Namespace: 3}, {Name: "globex", Namespace: 5}}, tenants)
+
+	_, err = m.CreateTenant(ctx, "acme", nil)
+	require.ErrorIs(t, err, dgo.ErrTenantExists)
+	require.Equal(t, []map[string]string{
+		{"$name": "acme"}, {"$name": "initech"}, nil, {"$name": "acme"},
+	}, vars)
+}
+
+// tenantNameRe matches the tenant names inlined in the upserts of TenantManager.
+var tenantNameRe = regexp.MustCompile(`eq\(dgo\.tenant\.name, ("(?:[^"\\]|\\.)*")\)`)
+
+// tenantName returns the name of the tenant a request of TenantManager is for.
+func tenantName(req *api.Request) string {
+	if m := tenantNameRe.FindStringSubmatch(req.Query); m != nil {
+		name, _ := strconv.Unquote(m[1])
+		return name
+	}
+	return req.Vars["$name"]
+}
+
+func TestTenantManagerHTTP(t *testing.T) {
+	var mu sync.Mutex
+	registered := map[string]bool{}
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req api.Request
+		body, _ := io.ReadAll(r.Body)
+		var jsonBody struct {
+			Query     string            `json:"query"`
+			Variables map[string]string `json:"variables"`
+		}
+		if json.Unmarshal(body, &jsonBody) == nil {
+			req.Query, req.Vars = jsonBody.Query, jsonBody.Variables
+		} else {
+			req.Query = string(body)
+		}
+		name := tenantName(&req)
+
+		mu.Lock()
+		defer mu.Unlock()
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/health":
+			_, _ = w.Write([]byte(`[{"version": "v25.0.0"}]`))
+		case "/login":
+			_, _ = w.Write([]byte(`{"data": {"accessJWT": "access", "refreshJWT": "refresh"}}`))
+		case "/alter":
+			_, _ = w.Write([]byte(`{"data": {"code": "Success"}}`))
+		case "/admin":
+			_, _ = w.Write([]byte(`{"data": {"addNamespace": {"namespaceId": 7},
+				"deleteNamespace": {"namespaceId": 7}}}`))
+		case "/query":
+			if registered[name] {
+				_, _ = fmt.Fprintf(w, `{"data": {"t": [{"name": %q, "namespace": 7}]}}`, name)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data": {"t": []}}`))
+		case "/mutate":
+			uids := "{}"
+			switch {
+			case name == "":
+			case strings.Contains(string(body), "delete"):
+				registered[name] = false
+			default:
+				registered[name] = true
+				uids = `{"tenant": "0x1"}`
+			}
+			_, _ = fmt.Fprintf(w, `{"data": {"code": "Success", "uids": %s}}`, uids)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	dg, err := dgo.Open("http://groot:password@" + srv.Listener.Addr().String())
+	require.NoError(t, err)
+	defer dg.Close()
+	m, err := dgo.NewTenantManager(dg, dgo.WithTenantCredentials("groot", "tenantpass"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	const name = `acme "corp"`
+	tenant, err := m.CreateTenant(ctx, name, nil)
+	require.NoError(t, err)
+	require.Equal(t, &dgo.Tenant{Name: name, Namespace: 7}, tenant)
+	_, err = m.Tenant(ctx, name)
+	require.NoError(t, err)
+	require.NoError(t, m.DropTenant(ctx, name))
+	_, err = m.Tenant(ctx, name)
+	require.ErrorIs(t, err, dgo.ErrTenantNotFound)
+	require.Contains(t, paths, "/admin")
+}
+
+func TestTenantManagerCreateOffline(t *testing.T) {
+	var mu sync.Mutex
+	var alters int
+	var logins, passwords []string
+	registered := map[string]bool{}
+	failDrop := true
+	srv := &fakeDgraphServer{
+		alter: func(context.Context, *api.Operation) (*api.Payload, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			alters++
+			return &api.Payload{}, nil
+		},
+		login: func(_ context.Context, req *api.LoginRequest) (*api.Response, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			logins = append(logins, fmt.Sprintf("%s:%s@%d", req.Userid, req.Password, req.Namespace))
+			jwt, err := proto.Marshal(&api.Jwt{AccessJwt: "access", RefreshJwt: "refresh"})
+			return &api.Response{Json: jwt}, err
+		},
+		query: func(_ context.Context, req *api.Request) (*api.Response, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			name := tenantName(req)
+			if len(req.Mutations) == 0 {
+				if registered[name] {
+					return &api.Response{Json: []byte(fmt.Sprintf(`{"t": [{"name": %q, "namespace": 7}]}`, name))}, nil
+				}
+				return &api.Response{Json: []byte(`{"t": []}`)}, nil
+			}
+			mut := req.Mutations[0]
+			switch {
+			case len(mut.DelNquads) > 0:
+				registered[name] = false
+			case name != "":
+				registered[name] = true
+				return &api.Response{Uids: map[string]string{"tenant": "0x1"}}, nil
+			default:
+				var set []map[string]any
+				if err := json.Unmarshal(mut.SetJson, &set); err != nil {
+					return nil, err
+				}
+				for _, nq := range set {
+					passwords = append(passwords, fmt.Sprint(nq["uid"], " ", nq["dgraph.password"]))
+				}
+			}
+			return &api.Response{}, nil
+		},
+		createNamespace: func(context.Context, *api.CreateNamespaceRequest) (*api.CreateNamespaceResponse, error) {
+			return &api.CreateNamespaceResponse{Namespace: 7}, nil
+		},
+		dropNamespace: func(context.Context, *api.DropNamespaceRequest) (*api.DropNamespaceResponse, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if failDrop {
+				return nil, status.Error(codes.Unavailable, "try again")
+			}
+			return &api.DropNamespaceResponse{}, nil
+		},
+	}
+	addr, _ := startFakeServer(t, "127.0.0.1:0", nil, srv)
+
+	dg, err := dgo.NewClient(addr,
+		dgo.WithGrpcOption(grpc.WithTransportCredentials(insecure.NewCredentials())))
+	require.NoError(t, err)
+	defer dg.Close()
+	m, err := dgo.NewTenantManager(dg, dgo.WithTenantCredentials("admin", "tenantpass"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, name := range []string{"acme", "globex"} {
+		_, err := m.CreateTenant(ctx, name, &dgo.TenantSpec{Schema: "name: string ."})
+		require.NoError(t, err)
+	}
+
+	// The registry schema is applied once, and the default password is replaced.
+	require.Equal(t, 3, alters)
+	require.Equal(t, []string{
+		"groot:password@7", "admin:tenantpass@7",
+		"groot:password@7", "admin:tenantpass@7",
+	}, logins)
+	require.Equal(t, []string{
+		"uid(groot) tenantpass", "_:user tenantpass",
+		"uid(groot) tenantpass", "_:user tenantpass",
+	}, passwords)
+
+	// A tenant whose namespace cannot be dropped stays registered.
+	require.ErrorContains(t, m.DropTenant(ctx, "acme"), "try again")
+	_, err = m.Tenant(ctx, "acme")
+	require.NoError(t, err)
+
+	mu.Lock()
+	failDrop = false
+	mu.Unlock()
+	require.NoError(t, m.DropTenant(ctx, "acme"))
+	_, err = m.Tenant(ctx, "acme")
+	require.ErrorIs(t, err, dgo.ErrTenantNotFound)
+}
+
+func TestTenantManagerOptions(t *testing.T) {
+	dg := dgo.NewDgraphClient(&fakeDgraphClient{})
+
+	tests := []struct {
+		opts []dgo.TenantOption
+		err  string
+	}{
+		{
+			opts: []dgo.TenantOption{dgo.WithTenantCredentials("groot", "")},
+			err:  "both username and password must be provided",
+		},
+		{err: "tenant credentials must be provided"},
+	}
+
+	for _, tc := range tests {
+		_, err := dgo.NewTenantManager(dg, tc.opts...)
+		require.ErrorContains(t, err, tc.err)
+	}
+
+	m, err := dgo.NewTenantManager(dg, dgo.WithTenantCredentials("groot", "tenantpass"))
+	require.NoError(t, err)
+	_, err = m.CreateTenant(context.Background(), "", nil)
+	require.ErrorContains(t, err, "tenant name cannot be empty")
+}
+
+func TestTenantManager(t *testing.T) {
+	dg, cancel := getDgraphClient()
+	defer cancel()
+
+	ctx := context.Background()
+	require.NoError(t, dg.DropAll(ctx))
+	m, err := dgo.NewTenantManager(dg, dgo.WithTenantCredentials("admin", "tenantpass"))
+	require.NoError(t, err)
+
+	spec := &dgo.TenantSpec{
+		Schema: `name: string @index(exact) .`,
+		Seed:   []*api.Mutation{{SetNquads: []byte(`_:a <name> "Alice" .`)}},
+	}
+	acme, err := m.CreateTenant(ctx, "acme", spec)
+	require.NoError(t, err)
+	defer func() { _ = m.DropTenant(ctx, "acme") }()
+	globex, err := m.CreateTenant(ctx, "globex", nil)
+	require.NoError(t, err)
+	defer func() { _ = m.DropTenant(ctx, "globex") }()
+	require.NotEqual(t, acme.Namespace, globex.Namespace)
+
+	_, err = m.CreateTenant(ctx, "acme", nil)
+	require.ErrorIs(t, err, dgo.ErrTenantExists)
+
+	// A failing spec leaves neither a namespace nor a registered tenant behind.
+	namespaces, err := dg.ListNamespaces(ctx)
+	require.NoError(t, err)
+	_, err = m.CreateTenant(ctx, "initech", &dgo.TenantSpec{Schema: "invalid schema"})
+	require.Error(t, err)
+	_, err = m.Tenant(ctx, "initech")
+	require.ErrorIs(t, err, dgo.ErrTenantNotFound)
+	after, err := dg.ListNamespaces(ctx)
+	require.NoError(t, err)
+	require.Len(t, after, len(namespaces))
+
+	tenants, err := m.ListTenants(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []*dgo.Tenant{acme, globex}, tenants)
+
+	tdg, err := m.Connect(ctx, acme)
+	require.NoError(t, err)
+	resp, err := tdg.NewReadOnlyTxn().Query(ctx, `{ q(func: has(name)) { name } }`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"q": [{"name": "Alice"}]}`, string(resp.Json))
+
+	// The default password of the groot user of the tenant is replaced.
+	_, err = dg.ForNamespace(ctx, acme.Namespace, "groot", "password")
+	require.Error(t, err)
+	_, err = dg.ForNamespace(ctx, acme.Namespace, "groot", "tenantpass")
+	require.NoError(t, err)
+
+	require.NoError(t, m.DropTenant(ctx, "globex"))
+	_, err = m.Tenant(ctx, "globex")
+	require.ErrorIs(t, err, dgo.ErrTenantNotFound)
+	namespaces, err = dg.ListNamespaces(ctx)
+	require.NoError(t, err)
+	require.NotContains(t, namespaces, globex.Namespace)
+}
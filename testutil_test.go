@@ -6,204 +6,138 @@
 package dgo_test
 
 import (
-	"bytes"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"io"
-	"net/http"
-	"strings"
+	"context"
+	"crypto/tls"
+	"net"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/dgraph-io/dgo/v250/protos/api"
 )
 
-// LoginParams stores the information needed to perform a login request.
-type LoginParams struct {
-	Endpoint   string
-	UserID     string
-	Passwd     string
-	Namespace  uint64
-	RefreshJwt string
-}
+// fakeDgraphClient is an in-memory api.DgraphClient used by tests that don't
+// need a running Dgraph cluster. Methods without a handler set panic.
+type fakeDgraphClient struct {
+	api.DgraphClient
 
-type GraphQLParams struct {
-	Query     string                 `json:"query"`
-	Variables map[string]interface{} `json:"variables"`
+	query         func(ctx context.Context, req *api.Request) (*api.Response, error)
+	commitOrAbort func(ctx context.Context, tc *api.TxnContext) (*api.TxnContext, error)
+	alter         func(ctx context.Context, op *api.Operation) (*api.Payload, error)
 }
 
-type HttpToken struct {
-	UserId       string
-	Password     string
-	AccessJwt    string
-	RefreshToken string
-}
+func (f *fakeDgraphClient) Query(ctx context.Context, req *api.Request,
+	_ ...grpc.CallOption) (*api.Response, error) {
 
-type GraphQLResponse struct {
-	Data       json.RawMessage        `json:"data,omitempty"`
-	Errors     GqlErrorList           `json:"errors,omitempty"`
-	Extensions map[string]interface{} `json:"extensions,omitempty"`
+	return f.query(ctx, req)
 }
 
-type GqlErrorList []*GqlError
+func (f *fakeDgraphClient) CommitOrAbort(ctx context.Context, tc *api.TxnContext,
+	_ ...grpc.CallOption) (*api.TxnContext, error) {
 
-type GqlError struct {
-	Message    string                 `json:"message"`
-	Path       []interface{}          `json:"path,omitempty"`
-	Extensions map[string]interface{} `json:"extensions,omitempty"`
+	return f.commitOrAbort(ctx, tc)
 }
 
-func MakeGQLRequestHelper(t *testing.T, endpoint string, params *GraphQLParams,
-	token *HttpToken) *GraphQLResponse {
-
-	b, err := json.Marshal(params)
-	require.NoError(t, err)
+func (f *fakeDgraphClient) Alter(ctx context.Context, op *api.Operation,
+	_ ...grpc.CallOption) (*api.Payload, error) {
 
-	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer(b))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
-	if token.AccessJwt != "" {
-		req.Header.Set("X-Dgraph-AccessToken", token.AccessJwt)
-	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	require.NoError(t, err)
-
-	defer resp.Body.Close()
-	b, err = io.ReadAll(resp.Body)
-	require.NoError(t, err)
+	return f.alter(ctx, op)
+}
 
-	var gqlResp GraphQLResponse
-	err = json.Unmarshal(b, &gqlResp)
-	require.NoError(t, err)
+// fakeDgraphServer is an in-process Dgraph gRPC server used by tests that don't
+// need a running Dgraph cluster. It answers CheckVersion and CommitOrAbort, and
+// Query, Login, Alter, RunDQL, CreateNamespace and DropNamespace using the handlers
+// if set.
+type fakeDgraphServer struct {
+	api.UnimplementedDgraphServer
+
+	query   func(ctx context.Context, req *api.Request) (*api.Response, error)
+	login   func(ctx context.Context, req *api.LoginRequest) (*api.Response, error)
+	alter   func(ctx context.Context, op *api.Operation) (*api.Payload, error)
+	runDQL  func(ctx context.Context, req *api.RunDQLRequest) (*api.Response, error)
+	queries atomic.Int64
+
+	createNamespace func(ctx context.Context, req *api.CreateNamespaceRequest) (*api.CreateNamespaceResponse, error)
+	dropNamespace   func(ctx context.Context, req *api.DropNamespaceRequest) (*api.DropNamespaceResponse, error)
+}
 
-	return &gqlResp
+func (*fakeDgraphServer) CheckVersion(context.Context, *api.Check) (*api.Version, error) {
+	return &api.Version{Tag: "fake"}, nil
 }
 
-func (errList GqlErrorList) Error() string {
-	var buf bytes.Buffer
-	for i, gqlErr := range errList {
-		if i > 0 {
-			buf.WriteByte('\n')
-		}
-		buf.WriteString(gqlErr.Error())
+func (s *fakeDgraphServer) Query(ctx context.Context, req *api.Request) (*api.Response, error) {
+	s.queries.Add(1)
+	if s.query != nil {
+		return s.query(ctx, req)
 	}
-	return buf.String()
+	return &api.Response{Json: []byte(`{}`)}, nil
 }
 
-func (gqlErr *GqlError) Error() string {
-	var buf bytes.Buffer
-	if gqlErr == nil {
-		return ""
+func (s *fakeDgraphServer) Login(ctx context.Context, req *api.LoginRequest) (*api.Response, error) {
+	if s.login != nil {
+		return s.login(ctx, req)
 	}
+	return s.UnimplementedDgraphServer.Login(ctx, req)
+}
 
-	buf.WriteString(gqlErr.Message)
-	return buf.String()
+func (*fakeDgraphServer) CommitOrAbort(_ context.Context, tc *api.TxnContext) (*api.TxnContext, error) {
+	return tc, nil
 }
 
-func MakeGQLRequest(t *testing.T, endpoint string, params *GraphQLParams,
-	token *HttpToken) *GraphQLResponse {
-	resp := MakeGQLRequestHelper(t, endpoint, params, token)
-	if len(resp.Errors) == 0 || !strings.Contains(resp.Errors.Error(), "Token is expired") {
-		return resp
+func (s *fakeDgraphServer) RunDQL(ctx context.Context, req *api.RunDQLRequest) (*api.Response, error) {
+	if s.runDQL != nil {
+		return s.runDQL(ctx, req)
 	}
-	var err error
-	token, err = HttpLogin(&LoginParams{
-		Endpoint:   endpoint,
-		UserID:     token.UserId,
-		Passwd:     token.Password,
-		RefreshJwt: token.RefreshToken,
-	})
-	require.NoError(t, err)
-	return MakeGQLRequestHelper(t, endpoint, params, token)
+	return s.UnimplementedDgraphServer.RunDQL(ctx, req)
 }
 
-// HttpLogin sends a HTTP request to the server
-// and returns the access JWT and refresh JWT extracted from
-// the HTTP response
-func HttpLogin(params *LoginParams) (*HttpToken, error) {
-	login := `mutation login($userId: String, $password: String, $namespace: Int, $refreshToken: String) {
-		login(userId: $userId, password: $password, namespace: $namespace, refreshToken: $refreshToken) {
-			response {
-				accessJWT
-				refreshJWT
-			}
-		}
-	}`
-
-	gqlParams := GraphQLParams{
-		Query: login,
-		Variables: map[string]interface{}{
-			"userId":       params.UserID,
-			"password":     params.Passwd,
-			"namespace":    params.Namespace,
-			"refreshToken": params.RefreshJwt,
-		},
-	}
-	body, err := json.Marshal(gqlParams)
-	if err != nil {
-		return nil, fmt.Errorf("unable to marshal body: %w", err)
+func (s *fakeDgraphServer) Alter(ctx context.Context, op *api.Operation) (*api.Payload, error) {
+	if s.alter != nil {
+		return s.alter(ctx, op)
 	}
+	return &api.Payload{}, nil
+}
 
-	req, err := http.NewRequest("POST", params.Endpoint, bytes.NewBuffer(body))
-	if err != nil {
-		return nil, fmt.Errorf("unable to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
+func (s *fakeDgraphServer) CreateNamespace(ctx context.Context,
+	req *api.CreateNamespaceRequest) (*api.CreateNamespaceResponse, error) {
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("login through curl failed: %w", err)
+	if s.createNamespace != nil {
+		return s.createNamespace(ctx, req)
 	}
-	defer resp.Body.Close()
+	return s.UnimplementedDgraphServer.CreateNamespace(ctx, req)
+}
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("unable to read from response: %w", err)
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("got non 200 response from the server with %s ", string(respBody))
-	}
-	var outputJson map[string]interface{}
-	if err := json.Unmarshal(respBody, &outputJson); err != nil {
-		var errOutputJson map[string]interface{}
-		if err := json.Unmarshal(respBody, &errOutputJson); err == nil {
-			if _, ok := errOutputJson["errors"]; ok {
-				return nil, fmt.Errorf("response error: %v", string(respBody))
-			}
-		}
-		return nil, fmt.Errorf("unable to unmarshal the output to get JWTs: %w", err)
-	}
+func (s *fakeDgraphServer) DropNamespace(ctx context.Context,
+	req *api.DropNamespaceRequest) (*api.DropNamespaceResponse, error) {
 
-	data, found := outputJson["data"].(map[string]interface{})
-	if !found {
-		return nil, fmt.Errorf("data entry found in the output: %w", err)
+	if s.dropNamespace != nil {
+		return s.dropNamespace(ctx, req)
 	}
+	return s.UnimplementedDgraphServer.DropNamespace(ctx, req)
+}
 
-	l, found := data["login"].(map[string]interface{})
-	if !found {
-		return nil, fmt.Errorf("data entry found in the output: %w", err)
-	}
+// startTLSServer starts a fakeDgraphServer listening on addr using the given TLS
+// configuration. It returns the address of the server along with a function to stop it.
+func startTLSServer(t *testing.T, addr string, cfg *tls.Config) (string, func()) {
+	return startFakeServer(t, addr, cfg, &fakeDgraphServer{})
+}
 
-	response, found := l["response"].(map[string]interface{})
-	if !found {
-		return nil, fmt.Errorf("data entry found in the output: %w", err)
-	}
+// startFakeServer is like startTLSServer for the given server. If cfg is nil,
+// the server doesn't use TLS.
+func startFakeServer(t *testing.T, addr string, cfg *tls.Config, srv *fakeDgraphServer) (string, func()) {
+	lis, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
 
-	newAccessJwt, found := response["accessJWT"].(string)
-	if !found || newAccessJwt == "" {
-		return nil, errors.New("no access JWT found in the output")
-	}
-	newRefreshJwt, found := response["refreshJWT"].(string)
-	if !found || newRefreshJwt == "" {
-		return nil, errors.New("no refresh JWT found in the output")
+	var opts []grpc.ServerOption
+	if cfg != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(cfg)))
 	}
-
-	return &HttpToken{
-		UserId:       params.UserID,
-		Password:     params.Passwd,
-		AccessJwt:    newAccessJwt,
-		RefreshToken: newRefreshJwt,
-	}, nil
+	s := grpc.NewServer(opts...)
+	api.RegisterDgraphServer(s, srv)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String(), s.Stop
 }
@@ -0,0 +1,341 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgo/v250"
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+// testCA is a certificate authority used to issue certificates for TLS tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+// writeCert writes the CA certificate in PEM format to the given file.
+func (ca *testCA) writeCert(t *testing.T, path string) {
+	writePEM(t, path, "CERTIFICATE", ca.cert.Raw)
+}
+
+// issue creates a certificate signed by the CA for the given common name and DNS names.
+func (ca *testCA) issue(t *testing.T, cn string, usage x509.ExtKeyUsage, dnsNames ...string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     dnsNames,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+// writeKeyPair writes the certificate and its private key in PEM format.
+func writeKeyPair(t *testing.T, cert tls.Certificate, certPath, keyPath string) {
+	keyDER, err := x509.MarshalECPrivateKey(cert.PrivateKey.(*ecdsa.PrivateKey))
+	require.NoError(t, err)
+	writePEM(t, certPath, "CERTIFICATE", cert.Certificate[0])
+	writePEM(t, keyPath, "EC PRIVATE KEY", keyDER)
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	// Write to a temporary file and rename it, so that readers never see partial files.
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, data, 0o600))
+	require.NoError(t, os.Rename(tmp, path))
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	serverCA := newTestCA(t, "server-ca")
+	clientCA := newTestCA(t, "client-ca")
+
+	caFile := filepath.Join(dir, "ca.pem")
+	serverCA.writeCert(t, caFile)
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	writeKeyPair(t, clientCA.issue(t, "client", x509.ExtKeyUsageClientAuth), certFile, keyFile)
+
+	addr, _ := startTLSServer(t, "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{serverCA.issue(t, "alpha", x509.ExtKeyUsageServerAuth, "alpha.internal")},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCA.pool,
+	})
+
+	dg, err := dgo.NewClient(addr,
+		dgo.WithCACertFile(caFile),
+		dgo.WithClientCertificate(certFile, keyFile),
+		dgo.WithServerName("alpha.internal"))
+	require.NoError(t, err)
+	dg.Close()
+
+	// The server name must match the certificate.
+	_, err = dgo.NewClient(addr,
+		dgo.WithCACertFile(caFile),
+		dgo.WithClientCertificate(certFile, keyFile),
+		dgo.WithServerName("other.internal"))
+	require.ErrorContains(t, err, "failed to ping")
+
+	// The server requires a client certificate.
+	_, err = dgo.NewClient(addr, dgo.WithCACertFile(caFile), dgo.WithServerName("alpha.internal"))
+	require.ErrorContains(t, err, "failed to ping")
+
+	// WithTLSConfig is modified by options applied after it.
+	dg, err = dgo.NewClient(addr,
+		dgo.WithTLSConfig(&tls.Config{ServerName: "alpha.internal", MinVersion: tls.VersionTLS12}),
+		dgo.WithCACertFile(caFile),
+		dgo.WithClientCertificate(certFile, keyFile))
+	require.NoError(t, err)
+	dg.Close()
+
+	q := url.Values{}
+	q.Set("sslrootcert", caFile)
+	q.Set("sslcert", certFile)
+	q.Set("sslkey", keyFile)
+	_, err = dgo.Open(fmt.Sprintf("dgraph://%s?%s", addr, q.Encode()))
+	// The server certificate is not valid for 127.0.0.1.
+	require.ErrorContains(t, err, "failed to ping")
+
+	// verify-ca only verifies the certificate chain, and so does require when the
+	// CA certificates are provided.
+	for _, mode := range []string{"verify-ca", "require"} {
+		q.Set("sslmode", mode)
+		dg, err = dgo.Open(fmt.Sprintf("dgraph://%s?%s", addr, q.Encode()))
+		require.NoError(t, err)
+		dg.Close()
+	}
+
+	otherCAFile := filepath.Join(dir, "other-ca.pem")
+	newTestCA(t, "other-ca").writeCert(t, otherCAFile)
+	q.Set("sslrootcert", otherCAFile)
+	for _, mode := range []string{"verify-ca", "require"} {
+		q.Set("sslmode", mode)
+		_, err = dgo.Open(fmt.Sprintf("dgraph://%s?%s", addr, q.Encode()))
+		require.ErrorContains(t, err, "failed to ping")
+	}
+
+	q.Del("sslrootcert")
+	dg, err = dgo.Open(fmt.Sprintf("dgraph://%s?%s", addr, q.Encode()))
+	require.NoError(t, err)
+	dg.Close()
+
+	dg, err = dgo.NewClient(addr,
+		dgo.WithCACertFile(caFile),
+		dgo.WithClientCertificate(certFile, keyFile),
+		dgo.WithVerifyCAOnly())
+	require.NoError(t, err)
+	dg.Close()
+}
+
+func TestOpenTLSParams(t *testing.T) {
+	var err error
+
+	_, err = dgo.Open("dgraph://localhost:9180?sslcert=client.pem")
+	require.ErrorContains(t, err, "invalid connection string: both sslcert and sslkey must be provided")
+
+	_, err = dgo.Open("dgraph://localhost:9180?sslkey=client.key")
+	require.ErrorContains(t, err, "invalid connection string: both sslcert and sslkey must be provided")
+
+	_, err = dgo.Open("dgraph://localhost:9180?sslmode=disable&sslrootcert=ca.pem")
+	require.ErrorContains(t, err, "certificate parameters cannot be used with sslmode=disable")
+
+	_, err = dgo.Open("dgraph://localhost:9180?sslrootcert=/does/not/exist.pem")
+	require.ErrorContains(t, err, "failed to read CA certificate file")
+
+	_, err = dgo.Open("dgraph://localhost:9180?sslmode=verify-full&sslcert=/no/cert.pem&sslkey=/no/key.pem")
+	require.ErrorContains(t, err, "failed to load client certificate")
+
+	badCA := filepath.Join(t.TempDir(), "bad.pem")
+	require.NoError(t, os.WriteFile(badCA, []byte("not a certificate"), 0o600))
+	_, err = dgo.Open("dgraph://localhost:9180?sslrootcert=" + badCA)
+	require.ErrorContains(t, err, "no valid certificates found in CA certificate file")
+}
+
+func TestTLSReload(t *testing.T) {
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+
+	// rotate issues new certificates for both the server and the client and returns
+	// the server TLS configuration that only accepts the new client certificate.
+	rotate := func() *tls.Config {
+		serverCA := newTestCA(t, "server-ca")
+		clientCA := newTestCA(t, "client-ca")
+		serverCA.writeCert(t, caFile)
+		writeKeyPair(t, clientCA.issue(t, "client", x509.ExtKeyUsageClientAuth), certFile, keyFile)
+		return &tls.Config{
+			Certificates: []tls.Certificate{serverCA.issue(t, "alpha", x509.ExtKeyUsageServerAuth, "alpha.internal")},
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			ClientCAs:    clientCA.pool,
+		}
+	}
+
+	addr, stop := startTLSServer(t, "127.0.0.1:0", rotate())
+	opts := []dgo.ClientOption{
+		dgo.WithCACertFile(caFile),
+		dgo.WithClientCertificate(certFile, keyFile),
+		dgo.WithServerName("alpha.internal"),
+	}
+	dg, err := dgo.NewClient(addr, append(opts, dgo.WithTLSReload(10*time.Millisecond))...)
+	require.NoError(t, err)
+	defer dg.Close()
+	dgStatic, err := dgo.NewClient(addr, opts...)
+	require.NoError(t, err)
+	defer dgStatic.Close()
+
+	// Restart the server with rotated certificates. The clients have to reconnect,
+	// and only the one reloading the files can do so.
+	stop()
+	startTLSServer(t, addr, rotate())
+	time.Sleep(20 * time.Millisecond)
+
+	checkVersion := func(dg *dgo.Dgraph) error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err := dg.GetAPIClients()[0].CheckVersion(ctx, &api.Check{})
+		return err
+	}
+	require.Eventually(t, func() bool { return checkVersion(dg) == nil }, 10*time.Second, 50*time.Millisecond)
+	require.Error(t, checkVersion(dgStatic))
+}
+
+func TestTLSReloadOptions(t *testing.T) {
+	var err error
+
+	_, err = dgo.NewClient("localhost:9180", dgo.WithTLSReload(0))
+	require.ErrorContains(t, err, "invalid TLS reload interval")
+
+	_, err = dgo.NewClient("localhost:9180", dgo.WithSystemCertPool(), dgo.WithTLSReload(time.Minute))
+	require.ErrorContains(t, err, "TLS reload requires a CA certificate file or a client certificate")
+
+	_, err = dgo.Open("dgraph://localhost:9180?sslreload=1m")
+	require.ErrorContains(t, err, "invalid connection string: sslreload requires sslrootcert or sslcert")
+
+	_, err = dgo.Open("dgraph://localhost:9180?sslrootcert=ca.pem&sslreload=often")
+	require.ErrorContains(t, err, "invalid sslreload interval")
+}
+
+func TestOpenMultiHost(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "ca")
+	caFile := filepath.Join(dir, "ca.pem")
+	ca.writeCert(t, caFile)
+
+	var srvs []*fakeDgraphServer
+	var addrs []string
+	for _, name := range []string{"a1.internal", "a2.internal", "a3.internal"} {
+		srv := &fakeDgraphServer{}
+		addr, _ := startFakeServer(t, "127.0.0.1:0", &tls.Config{
+			Certificates: []tls.Certificate{ca.issue(t, name, x509.ExtKeyUsageServerAuth, name)},
+		}, srv)
+		srvs = append(srvs, srv)
+		addrs = append(addrs, addr)
+	}
+
+	connStr := fmt.Sprintf("dgraph://%s?sslrootcert=%s&sslservername=%s&loadbalance=roundrobin",
+		strings.Join(addrs, ","), url.QueryEscape(caFile), "a1.internal,a2.internal,a3.internal")
+	dg, err := dgo.Open(connStr)
+	require.NoError(t, err)
+	defer dg.Close()
+	require.Len(t, dg.GetAPIClients(), 3)
+
+	ctx := context.Background()
+	for range 6 {
+		_, err := dg.NewReadOnlyTxn().Query(ctx, `{ q(func: uid(1)) { uid } }`)
+		require.NoError(t, err)
+	}
+	for _, srv := range srvs {
+		require.Equal(t, int64(2), srv.queries.Load())
+	}
+
+	// A single server name is used for all the hosts.
+	connStr = fmt.Sprintf("dgraph://%s?sslrootcert=%s&sslservername=a1.internal",
+		strings.Join(addrs, ","), url.QueryEscape(caFile))
+	dg, err = dgo.Open(connStr)
+	require.NoError(t, err)
+	defer dg.Close()
+	for i, dc := range dg.GetAPIClients() {
+		_, err := dc.CheckVersion(ctx, &api.Check{})
+		if i == 0 {
+			require.NoError(t, err)
+		} else {
+			require.Error(t, err)
+		}
+	}
+}
+
+func TestOpenMultiHostParams(t *testing.T) {
+	var err error
+
+	_, err = dgo.Open("dgraph://localhost,localhost:9180")
+	require.ErrorContains(t, err, "invalid connection string: host url must have both host and port")
+
+	_, err = dgo.Open("dgraph://localhost:9180,localhost:")
+	require.ErrorContains(t, err, "invalid connection string: missing port after port-separator colon")
+
+	_, err = dgo.Open("dgraph://localhost:9180,localhost:9181?loadbalance=fastest")
+	require.ErrorContains(t, err, "invalid load balancing policy: fastest (must be one of random, roundrobin)")
+
+	_, err = dgo.Open("dgraph://localhost:9180,localhost:9181?sslservername=a1")
+	require.ErrorContains(t, err, "invalid connection string: sslservername cannot be used with sslmode=disable")
+
+	_, err = dgo.Open("dgraph://localhost:9180,localhost:9181,localhost:9182?sslmode=require&sslservername=a1,a2")
+	require.ErrorContains(t, err, "sslservername must have one name or one for each host")
+}
@@ -0,0 +1,322 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	defaultTokenRefreshBefore = time.Minute
+	// maxTokenRetryBackoff caps the delay after which a failed refresh is retried.
+	maxTokenRetryBackoff = 30 * time.Second
+)
+
+// Token is an access token along with the time it expires at.
+type Token struct {
+	Value string
+	// Expiry is the time the token expires at. The zero value means that the
+	// token doesn't expire.
+	Expiry time.Time
+}
+
+// valid reports whether the token can be used for at least the given duration.
+func (t *Token) valid(within time.Duration) bool {
+	return t != nil && t.Value != "" && (t.Expiry.IsZero() || time.Now().Add(within).Before(t.Expiry))
+}
+
+// TokenSource provides the tokens used for authenticating requests. It is called
+// for every request, so implementations that fetch tokens remotely should be
+// wrapped using NewCachingTokenSource.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// TokenSourceFunc is an adapter to allow the use of ordinary functions as a TokenSource.
+type TokenSourceFunc func(ctx context.Context) (*Token, error)
+
+// Token calls f(ctx).
+func (f TokenSourceFunc) Token(ctx context.Context) (*Token, error) {
+	return f(ctx)
+}
+
+type cachingTokenSource struct {
+	src           TokenSource
+	refreshBefore time.Duration
+
+	mu         sync.Mutex
+	token      *Token
+	refreshing chan struct{} // closed once the ongoing refresh completes, if any
+	failures   int           // number of consecutive failed refreshes
+	retryAt    time.Time     // the time before which a failed refresh isn't retried
+	err        error         // the error of the last failed refresh
+}
+
+// NewCachingTokenSource returns a TokenSource that caches the token provided by src,
+// fetching a new one once the cached token is within refreshBefore of its expiry. If
+// refreshing fails, the cached token continues to be used until it expires, and the
+// refresh is retried with an exponential backoff of up to 30s, during which the
+// error is returned if the cached token has expired. Only one caller refreshes the
+// token at a time, the others use the cached token while it is valid.
+func NewCachingTokenSource(src TokenSource, refreshBefore time.Duration) TokenSource {
+	return &cachingTokenSource{src: src, refreshBefore: refreshBefore}
+}
+
+func (c *cachingTokenSource) Token(ctx context.Context) (*Token, error) {
+	c.mu.Lock()
+	for {
+		if c.token.valid(c.refreshBefore) {
+			defer c.mu.Unlock()
+			return c.token, nil
+		}
+		if c.refreshing == nil {
+			break
+		}
+		if c.token.valid(0) {
+			defer c.mu.Unlock()
+			return c.token, nil
+		}
+		refreshing := c.refreshing
+		c.mu.Unlock()
+		select {
+		case <-refreshing:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		c.mu.Lock()
+	}
+	if time.Now().Before(c.retryAt) {
+		defer c.mu.Unlock()
+		if c.token.valid(0) {
+			return c.token, nil
+		}
+		return nil, c.err
+	}
+	refreshing := make(chan struct{})
+	c.refreshing = refreshing
+	c.mu.Unlock()
+
+	token, err := c.src.Token(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshing = nil
+	close(refreshing)
+	if err != nil {
+		// Errors of the context of the caller are not failures of src.
+		if ctx.Err() == nil {
+			c.failures++
+			backoff := min(time.Second<<min(c.failures-1, 5), maxTokenRetryBackoff)
+			c.retryAt, c.err = time.Now().Add(backoff), err
+		}
+		if c.token.valid(0) {
+			return c.token, nil
+		}
+		return nil, err
+	}
+	c.token, c.failures, c.retryAt, c.err = token, 0, time.Time{}, nil
+	return token, nil
+}
+
+type fileTokenSource struct {
+	path string
+
+	mu      sync.Mutex
+	version fileVersion
+	token   *Token
+}
+
+// NewFileTokenSource returns a TokenSource that reads the token from the given file,
+// such as a mounted Kubernetes secret. The file is read again whenever it changes.
+// Leading and trailing whitespace is trimmed from the token.
+func NewFileTokenSource(path string) TokenSource {
+	return &fileTokenSource{path: path}
+}
+
+func (f *fileTokenSource) Token(context.Context) (*Token, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fi, err := os.Stat(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+	version := fileVersion{modTime: fi.ModTime(), size: fi.Size()}
+	if f.token != nil && f.version == version {
+		return f.token, nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return nil, fmt.Errorf("token file [%s] is empty", f.path)
+	}
+	f.token, f.version = &Token{Value: value}, version
+	return f.token, nil
+}
+
+// ClientCredentialsConfig describes an OAuth2 client credentials flow used to obtain
+// access tokens from an identity provider.
+type ClientCredentialsConfig struct {
+	// TokenURL is the token endpoint of the identity provider.
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// EndpointParams are additional parameters for requests to the token endpoint,
+	// e.g. audience.
+	EndpointParams url.Values
+	// HTTPClient is used for requests to the token endpoint. If nil,
+	// http.DefaultClient is used.
+	HTTPClient *http.Client
+	// RefreshBefore is how long before the expiry a token is refreshed, one minute
+	// by default.
+	RefreshBefore time.Duration
+}
+
+// TokenSource returns a caching TokenSource that obtains tokens using the
+// client credentials flow.
+func (c *ClientCredentialsConfig) TokenSource() TokenSource {
+	refreshBefore := c.RefreshBefore
+	if refreshBefore == 0 {
+		refreshBefore = defaultTokenRefreshBefore
+	}
+	return NewCachingTokenSource(TokenSourceFunc(c.fetchToken), refreshBefore)
+}
+
+func (c *ClientCredentialsConfig) fetchToken(ctx context.Context) (*Token, error) {
+	form := url.Values{}
+	maps.Copy(form, c.EndpointParams)
+	form.Set("grant_type", "client_credentials")
+	if len(c.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read token response: %w", err)
+	}
+
+	var tr struct {
+		AccessToken      string `json:"access_token"`
+		TokenType        string `json:"token_type"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &tr); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("unable to parse token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || tr.Error != "" {
+		if tr.Error != "" {
+			return nil, fmt.Errorf("token request failed with status %d: %s %s",
+				resp.StatusCode, tr.Error, tr.ErrorDescription)
+		}
+		return nil, fmt.Errorf("token request failed with status %d", resp.StatusCode)
+	}
+	if tr.AccessToken == "" {
+		return nil, errors.New("no access token found in token response")
+	}
+
+	token := &Token{Value: tr.AccessToken}
+	if tr.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// tokenSourceCreds attaches the tokens provided by a TokenSource to every request
+// in the Authorization header, prefixed by the given scheme if any.
+type tokenSourceCreds struct {
+	src    TokenSource
+	scheme string
+}
+
+func (c *tokenSourceCreds) GetRequestMetadata(ctx context.Context, uri ...string) (
+	map[string]string, error) {
+
+	token, err := c.src.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+	if c.scheme == "" {
+		return map[string]string{"Authorization": token.Value}, nil
+	}
+	return map[string]string{"Authorization": c.scheme + " " + token.Value}, nil
+}
+
+func (c *tokenSourceCreds) RequireTransportSecurity() bool {
+	return true
+}
+
+func (c *tokenSourceCreds) setHTTPAuth(ctx context.Context, h http.Header) error {
+	token, err := c.src.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get token: %w", err)
+	}
+	if c.scheme == "" {
+		h.Set(apiKeyHeader, token.Value)
+	} else {
+		h.Set("Authorization", c.scheme+" "+token.Value)
+	}
+	return nil
+}
+
+// WithTokenSource uses the tokens provided by src as Bearer Tokens in the HTTP
+// Authorization header for authentication against a Dgraph Cluster. Unlike
+// WithBearerToken, the token can change during the lifetime of the client.
+func WithTokenSource(src TokenSource) ClientOption {
+	return func(o *clientOptions) error {
+		creds := &tokenSourceCreds{src: src, scheme: "Bearer"}
+		o.gopts = append(o.gopts, grpc.WithPerRPCCredentials(creds))
+		o.httpAuth = append(o.httpAuth, creds)
+		return nil
+	}
+}
+
+// WithAPIKeySource is like WithDgraphAPIKey, but uses the keys provided by src,
+// so that the key can change during the lifetime of the client.
+func WithAPIKeySource(src TokenSource) ClientOption {
+	return func(o *clientOptions) error {
+		creds := &tokenSourceCreds{src: src}
+		o.gopts = append(o.gopts, grpc.WithPerRPCCredentials(creds))
+		o.httpAuth = append(o.httpAuth, creds)
+		return nil
+	}
+}
@@ -0,0 +1,284 @@
+/*
+ * SPDX-FileCopyrightText: © 2017-2025 Istari Digital, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package dgo_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dgraph-io/dgo/v250"
+	"github.com/dgraph-io/dgo/v250/graphql"
+	"github.com/dgraph-io/dgo/v250/protos/api"
+)
+
+func TestCachingTokenSource(t *testing.T) {
+	var calls atomic.Int64
+	var fail atomic.Bool
+	src := dgo.TokenSourceFunc(func(context.Context) (*dgo.Token, error) {
+		if fail.Load() {
+			return nil, errors.New("identity provider unavailable")
+		}
+		n := calls.Add(1)
+		return &dgo.Token{Value: fmt.Sprintf("token-%d", n), Expiry: time.Now().Add(2 * time.Second)}, nil
+	})
+	ts := dgo.NewCachingTokenSource(src, 1500*time.Millisecond)
+	ctx := context.Background()
+
+	token, err := ts.Token(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "token-1", token.Value)
+	token, err = ts.Token(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "token-1", token.Value)
+
+	// The token is refreshed before it expires.
+	time.Sleep(600 * time.Millisecond)
+	token, err = ts.Token(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "token-2", token.Value)
+
+	// If refreshing fails, the cached token is used until it expires.
+	fail.Store(true)
+	time.Sleep(600 * time.Millisecond)
+	token, err = ts.Token(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "token-2", token.Value)
+
+	time.Sleep(1500 * time.Millisecond)
+	_, err = ts.Token(ctx)
+	require.ErrorContains(t, err, "identity provider unavailable")
+}
+
+func TestCachingTokenSourceBackoff(t *testing.T) {
+	var calls atomic.Int64
+	block := make(chan struct{})
+	src := dgo.TokenSourceFunc(func(context.Context) (*dgo.Token, error) {
+		switch calls.Add(1) {
+		case 1:
+			return &dgo.Token{Value: "token-1", Expiry: time.Now().Add(300 * time.Millisecond)}, nil
+		case 2:
+			<-block
+		}
+		return nil, errors.New("identity provider unavailable")
+	})
+	ts := dgo.NewCachingTokenSource(src, time.Hour)
+	ctx := context.Background()
+
+	token, err := ts.Token(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "token-1", token.Value)
+
+	// The cached token is used while it is being refreshed.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		token, err := ts.Token(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "token-1", token.Value)
+	}()
+	require.Eventually(t, func() bool { return calls.Load() == 2 }, time.Second, time.Millisecond)
+	for range 10 {
+		token, err = ts.Token(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "token-1", token.Value)
+	}
+	close(block)
+	<-done
+
+	// Failed refreshes are not retried until the backoff of 1s expires.
+	time.Sleep(350 * time.Millisecond)
+	for range 10 {
+		_, err = ts.Token(ctx)
+		require.ErrorContains(t, err, "identity provider unavailable")
+	}
+	require.EqualValues(t, 2, calls.Load())
+
+	time.Sleep(time.Second)
+	_, err = ts.Token(ctx)
+	require.ErrorContains(t, err, "identity provider unavailable")
+	require.EqualValues(t, 3, calls.Load())
+}
+
+func TestFileTokenSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	ts := dgo.NewFileTokenSource(path)
+	ctx := context.Background()
+
+	_, err := ts.Token(ctx)
+	require.ErrorContains(t, err, "failed to read token file")
+
+	require.NoError(t, os.WriteFile(path, []byte("first\n"), 0o600))
+	token, err := ts.Token(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "first", token.Value)
+
+	require.NoError(t, os.WriteFile(path, []byte("second-token\n"), 0o600))
+	token, err = ts.Token(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "second-token", token.Value)
+
+	require.NoError(t, os.WriteFile(path, []byte("\n"), 0o600))
+	_, err = ts.Token(ctx)
+	require.ErrorContains(t, err, "is empty")
+}
+
+func TestClientCredentialsTokenSource(t *testing.T) {
+	var requests atomic.Int64
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		require.NoError(t, r.ParseForm())
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "client" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"error": "invalid_client", "error_description": "bad credentials"})
+			return
+		}
+		require.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		require.Equal(t, "read write", r.PostForm.Get("scope"))
+		require.Equal(t, "dgraph", r.PostForm.Get("audience"))
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": fmt.Sprintf("access-%d", requests.Load()),
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+	defer idp.Close()
+
+	cfg := &dgo.ClientCredentialsConfig{
+		TokenURL:       idp.URL,
+		ClientID:       "client",
+		ClientSecret:   "s3cret",
+		Scopes:         []string{"read", "write"},
+		EndpointParams: map[string][]string{"audience": {"dgraph"}},
+	}
+	ts := cfg.TokenSource()
+	ctx := context.Background()
+
+	for range 3 {
+		token, err := ts.Token(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "access-1", token.Value)
+		require.WithinDuration(t, time.Now().Add(time.Hour), token.Expiry, time.Minute)
+	}
+	require.Equal(t, int64(1), requests.Load())
+
+	cfg.ClientSecret = "wrong"
+	_, err := cfg.TokenSource().Token(ctx)
+	require.ErrorContains(t, err, "token request failed with status 401: invalid_client bad credentials")
+}
+
+func TestWithTokenSource(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca.writeCert(t, caFile)
+
+	var authHeaders []string
+	srv := &fakeDgraphServer{query: func(ctx context.Context, req *api.Request) (*api.Response, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		authHeaders = append(authHeaders, md.Get("authorization")...)
+		return &api.Response{Json: []byte(`{}`)}, nil
+	}}
+	addr, _ := startFakeServer(t, "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{ca.issue(t, "alpha", x509.ExtKeyUsageServerAuth, "alpha.internal")},
+	}, srv)
+
+	var n atomic.Int64
+	src := dgo.TokenSourceFunc(func(context.Context) (*dgo.Token, error) {
+		return &dgo.Token{Value: fmt.Sprintf("token-%d", n.Add(1))}, nil
+	})
+	ctx := context.Background()
+
+	dg, err := dgo.NewClient(addr, dgo.WithCACertFile(caFile), dgo.WithServerName("alpha.internal"),
+		dgo.WithTokenSource(src))
+	require.NoError(t, err)
+	defer dg.Close()
+	for range 2 {
+		_, err := dg.NewReadOnlyTxn().Query(ctx, "{}")
+		require.NoError(t, err)
+	}
+
+	dgKey, err := dgo.NewClient(addr, dgo.WithCACertFile(caFile), dgo.WithServerName("alpha.internal"),
+		dgo.WithAPIKeySource(src))
+	require.NoError(t, err)
+	defer dgKey.Close()
+	_, err = dgKey.NewReadOnlyTxn().Query(ctx, "{}")
+	require.NoError(t, err)
+
+	// The first token of each client is used by CheckVersion when it is created.
+	require.Equal(t, []string{"Bearer token-2", "Bearer token-3", "token-5"}, authHeaders)
+
+	failing := dgo.TokenSourceFunc(func(context.Context) (*dgo.Token, error) {
+		return nil, errors.New("no token")
+	})
+	_, err = dgo.NewClient(addr, dgo.WithCACertFile(caFile), dgo.WithServerName("alpha.internal"),
+		dgo.WithTokenSource(failing))
+	require.ErrorContains(t, err, "failed to get token: no token")
+}
+
+func TestAuthHeadersOffline(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca.writeCert(t, caFile)
+	srv := &fakeDgraphServer{login: func(context.Context, *api.LoginRequest) (*api.Response, error) {
+		jwt, err := proto.Marshal(&api.Jwt{AccessJwt: "access", RefreshJwt: "refresh"})
+		return &api.Response{Json: jwt}, err
+	}}
+	addr, _ := startFakeServer(t, "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{ca.issue(t, "alpha", x509.ExtKeyUsageServerAuth, "alpha.internal")},
+	}, srv)
+
+	var headers []http.Header
+	gqlSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header)
+		_, _ = w.Write([]byte(`{"data": {}}`))
+	}))
+	defer gqlSrv.Close()
+
+	ctx := context.Background()
+	dg, err := dgo.NewClient(addr, dgo.WithCACertFile(caFile), dgo.WithServerName("alpha.internal"),
+		dgo.WithBearerToken("token"))
+	require.NoError(t, err)
+	defer dg.Close()
+	ns, err := dg.ForNamespace(ctx, 1, "groot", "password")
+	require.NoError(t, err)
+
+	dgKey, err := dgo.NewClient(addr, dgo.WithCACertFile(caFile), dgo.WithServerName("alpha.internal"),
+		dgo.WithAPIKeySource(dgo.TokenSourceFunc(func(context.Context) (*dgo.Token, error) {
+			return &dgo.Token{Value: "key"}, nil
+		})))
+	require.NoError(t, err)
+	defer dgKey.Close()
+
+	for _, auth := range []graphql.Auth{dg, ns, dgKey} {
+		c, err := graphql.NewClient(gqlSrv.URL, graphql.WithAuth(auth))
+		require.NoError(t, err)
+		require.NoError(t, c.Execute(ctx, `{ q }`, nil, nil))
+	}
+
+	require.Len(t, headers, 3)
+	require.Equal(t, "Bearer token", headers[0].Get("Authorization"))
+	require.Empty(t, headers[0].Get("X-Dgraph-AccessToken"))
+	require.Equal(t, "Bearer token", headers[1].Get("Authorization"))
+	require.Equal(t, "access", headers[1].Get("X-Dgraph-AccessToken"))
+	require.Equal(t, "key", headers[2].Get("Dg-Auth"))
+	require.Empty(t, headers[2].Get("Authorization"))
+}
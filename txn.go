@@ -8,6 +8,7 @@ package dgo
 import (
 	"context"
 	"errors"
+	"sync"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -35,8 +36,22 @@ var (
 //     that at least one of these methods is called to clean up resources. Discard
 //     is a no-op if Commit has already been called, so it's safe to defer a call
 //     to Discard immediately after NewTxn.
+//
+// A Txn is safe for concurrent use by multiple goroutines. Query and Mutate calls
+// may run in parallel, and the transaction contexts returned by the server are
+// merged atomically. If the start timestamp is not yet known, the first request
+// obtains it before the others are sent, so that all requests share the same
+// snapshot. Commit and Discard wait for in-flight requests to complete, and any
+// request made after them returns ErrFinished.
 type Txn struct {
-	context *api.TxnContext
+	// reqMu is held for reading by in-flight requests and for writing by
+	// Commit and Discard.
+	reqMu sync.RWMutex
+
+	// mu protects the fields below.
+	mu       sync.Mutex
+	context  *api.TxnContext
+	starting chan struct{} // closed once the first request completes
 
 	keys  map[string]struct{}
 	preds map[string]struct{}
@@ -46,19 +61,31 @@ type Txn struct {
 	readOnly   bool
 	bestEffort bool
 
-	dg *Dgraph
-	dc api.DgraphClient
+	dg       *Dgraph
+	dc       api.DgraphClient
+	index    int // of dc in dg.dc
+	endpoint string
 }
 
 // NewTxn creates a new transaction.
 func (d *Dgraph) NewTxn() *Txn {
-	return &Txn{
+	return d.newTxn(d.anyClientIndex())
+}
+
+// newTxn creates a new transaction pinned to the i-th client of d.
+func (d *Dgraph) newTxn(i int) *Txn {
+	txn := &Txn{
 		dg:      d,
-		dc:      d.anyClient(),
+		dc:      d.dc[i],
+		index:   i,
 		context: &api.TxnContext{},
 		keys:    make(map[string]struct{}),
 		preds:   make(map[string]struct{}),
 	}
+	if i < len(d.endpoints) {
+		txn.endpoint = d.endpoints[i]
+	}
+	return txn
 }
 
 // NewReadOnlyTxn sets the txn to readonly transaction.
@@ -68,12 +95,32 @@ func (d *Dgraph) NewReadOnlyTxn() *Txn {
 	return txn
 }
 
+// NewReadOnlyTxnAt creates a read-only transaction that reads the snapshot of the
+// graph at the given start timestamp, instead of the one assigned by the server on
+// the first query. The timestamp could be obtained from another transaction using
+// StartTs, from Response.Txn or from AllocateTimestamps. This allows multiple
+// goroutines or processes to read a consistent snapshot of the graph.
+func (d *Dgraph) NewReadOnlyTxnAt(startTs uint64) *Txn {
+	txn := d.NewReadOnlyTxn()
+	txn.context.StartTs = startTs
+	return txn
+}
+
+// StartTs returns the start timestamp of the transaction. It is zero until the
+// first request in the transaction has completed, unless the transaction was
+// created using NewReadOnlyTxnAt.
+func (txn *Txn) StartTs() uint64 {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.context.StartTs
+}
+
 // BestEffort enables best effort in read-only queries. This will ask the Dgraph Alpha
 // to try to get timestamps from memory in a best effort to reduce the number of outbound
 // requests to Zero. This may yield improved latencies in read-bound datasets.
 //
-// This method will panic if the transaction is not read-only.
-// Returns the transaction itself.
+// This method will panic if the transaction is not read-only. It must be called
+// before the transaction is used. Returns the transaction itself.
 func (txn *Txn) BestEffort() *Txn {
 	if !txn.readOnly {
 		panic("Best effort only works for read-only queries.")
@@ -105,7 +152,6 @@ func (txn *Txn) QueryWithVars(ctx context.Context, q string, vars map[string]str
 	req := &api.Request{
 		Query:      q,
 		Vars:       vars,
-		StartTs:    txn.context.StartTs,
 		ReadOnly:   txn.readOnly,
 		BestEffort: txn.bestEffort,
 		RespFormat: api.Request_JSON,
@@ -121,7 +167,6 @@ func (txn *Txn) QueryRDFWithVars(ctx context.Context, q string, vars map[string]
 	req := &api.Request{
 		Query:      q,
 		Vars:       vars,
-		StartTs:    txn.context.StartTs,
 		ReadOnly:   txn.readOnly,
 		BestEffort: txn.bestEffort,
 		RespFormat: api.Request_RDF,
@@ -141,7 +186,6 @@ func (txn *Txn) QueryRDFWithVars(ctx context.Context, q string, vars map[string]
 // future operations on it will fail.
 func (txn *Txn) Mutate(ctx context.Context, mu *api.Mutation) (*api.Response, error) {
 	req := &api.Request{
-		StartTs:   txn.context.StartTs,
 		Mutations: []*api.Mutation{mu},
 		CommitNow: mu.CommitNow,
 	}
@@ -150,20 +194,46 @@ func (txn *Txn) Mutate(ctx context.Context, mu *api.Mutation) (*api.Response, er
 
 // Do executes a query followed by one or more than one mutations.
 func (txn *Txn) Do(ctx context.Context, req *api.Request) (*api.Response, error) {
-	if txn.finished {
-		return nil, ErrFinished
+	if txn.readOnly && txn.dg.cache != nil && len(req.Mutations) == 0 {
+		return txn.cachedQuery(ctx, req)
 	}
 
-	if len(req.Mutations) > 0 {
-		if txn.readOnly {
-			return nil, ErrReadOnly
-		}
-		txn.mutated = true
+	resp, err := txn.do(ctx, req)
+	if err == nil || len(req.Mutations) == 0 ||
+		errors.Is(err, ErrFinished) || errors.Is(err, ErrReadOnly) {
+
+		return resp, err
+	}
+
+	// Ignore error, user should see the original error.
+	_ = txn.Discard(ctx)
+
+	// If the transaction was aborted, return the right error
+	// so the caller can handle it.
+	if s, ok := status.FromError(err); ok && s.Code() == codes.Aborted {
+		err = ErrAborted
+	}
+	return nil, err
+}
+
+func (txn *Txn) do(ctx context.Context, req *api.Request) (*api.Response, error) {
+	txn.reqMu.RLock()
+	defer txn.reqMu.RUnlock()
+
+	starting, err := txn.begin(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if starting != nil {
+		defer func() {
+			txn.mu.Lock()
+			close(starting)
+			txn.starting = nil
+			txn.mu.Unlock()
+		}()
 	}
 
 	ctx = txn.dg.getContext(ctx)
-	req.StartTs = txn.context.StartTs
-	req.Hash = txn.context.Hash
 
 	// Append the GRPC Response headers to the responses. Needed for Cloud.
 	appendHdr := func(hdrs *metadata.MD, resp *api.Response) {
@@ -175,10 +245,23 @@ func (txn *Txn) Do(ctx context.Context, req *api.Request) (*api.Response, error)
 		}
 	}
 
-	var responseHeaders metadata.MD
-	resp, err := txn.dc.Query(ctx, req, grpc.Header(&responseHeaders))
-	appendHdr(&responseHeaders, resp)
+	query := func(ctx context.Context, attempt int) (*api.Response, error) {
+		call := &Call{Method: "Query", Request: req, Txn: txn, Attempt: attempt}
+		return intercept(ctx, txn.dg, call, func(ctx context.Context) (*api.Response, error) {
+			var responseHeaders metadata.MD
+			var resp *api.Response
+			var err error
+			if txn.readOnly && txn.dg.hedger != nil && len(txn.dg.dc) > 1 {
+				resp, err = txn.hedgedQuery(ctx, req, &responseHeaders)
+			} else {
+				resp, err = txn.dc.Query(ctx, req, grpc.Header(&responseHeaders))
+			}
+			appendHdr(&responseHeaders, resp)
+			return resp, err
+		})
+	}
 
+	resp, err := query(ctx, 1)
 	if isJwtExpired(err) {
 		err = txn.dg.retryLogin(ctx)
 		if err != nil {
@@ -186,32 +269,65 @@ func (txn *Txn) Do(ctx context.Context, req *api.Request) (*api.Response, error)
 		}
 
 		ctx = txn.dg.getContext(ctx)
-		var responseHeaders metadata.MD
-		resp, err = txn.dc.Query(ctx, req, grpc.Header(&responseHeaders))
-		appendHdr(&responseHeaders, resp)
+		resp, err = query(ctx, 2)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	if err == nil {
-		if req.CommitNow {
-			txn.finished = true
-		}
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
 
-		err = txn.mergeContext(resp.GetTxn())
-		return resp, err
+	if req.CommitNow {
+		txn.finished = true
+	}
+	if err := txn.mergeContext(resp.GetTxn()); err != nil {
+		return nil, err
 	}
+	if req.CommitNow && txn.dg.cache != nil {
+		txn.dg.cache.invalidate(resp.GetTxn().GetPreds())
+	}
+	return resp, nil
+}
 
-	if len(req.Mutations) > 0 {
-		// Ignore error, user should see the original error.
-		_ = txn.Discard(ctx)
+// begin validates the request against the state of the transaction and fills in its
+// start timestamp and hash. If the start timestamp is not yet known, it waits for an
+// in-flight request to obtain it or, if there is none, returns a channel that the
+// caller must close once its request completes.
+func (txn *Txn) begin(ctx context.Context, req *api.Request) (chan struct{}, error) {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+
+	for txn.context.StartTs == 0 && txn.starting != nil {
+		starting := txn.starting
+		txn.mu.Unlock()
+		select {
+		case <-starting:
+		case <-ctx.Done():
+			txn.mu.Lock()
+			return nil, ctx.Err()
+		}
+		txn.mu.Lock()
+	}
 
-		// If the transaction was aborted, return the right error
-		// so the caller can handle it.
-		if s, ok := status.FromError(err); ok && s.Code() == codes.Aborted {
-			err = ErrAborted
+	if txn.finished {
+		return nil, ErrFinished
+	}
+	if len(req.Mutations) > 0 {
+		if txn.readOnly {
+			return nil, ErrReadOnly
 		}
+		txn.mutated = true
 	}
 
-	return nil, err
+	req.StartTs = txn.context.StartTs
+	req.Hash = txn.context.Hash
+
+	if txn.context.StartTs != 0 {
+		return nil, nil
+	}
+	txn.starting = make(chan struct{})
+	return txn.starting, nil
 }
 
 // Commit commits any mutations that have been made in the transaction.
@@ -222,6 +338,9 @@ func (txn *Txn) Do(ctx context.Context, req *api.Request) (*api.Response, error)
 // It's up to the user to decide if they wish to retry.
 // In this case, the user should create a new transaction.
 func (txn *Txn) Commit(ctx context.Context) error {
+	txn.reqMu.Lock()
+	defer txn.reqMu.Unlock()
+
 	switch {
 	case txn.readOnly:
 		return ErrReadOnly
@@ -229,7 +348,7 @@ func (txn *Txn) Commit(ctx context.Context) error {
 		return ErrFinished
 	}
 
-	err := txn.commitOrAbort(ctx)
+	err := txn.commitOrAbort(ctx, false)
 	if s, ok := status.FromError(err); ok && s.Code() == codes.Aborted {
 		err = ErrAborted
 	}
@@ -246,11 +365,14 @@ func (txn *Txn) Commit(ctx context.Context) error {
 // is unavailable. In these cases, the server will eventually do the
 // transaction clean up itself without any intervention from the client.
 func (txn *Txn) Discard(ctx context.Context) error {
-	txn.context.Aborted = true
-	return txn.commitOrAbort(ctx)
+	txn.reqMu.Lock()
+	defer txn.reqMu.Unlock()
+
+	return txn.commitOrAbort(ctx, true)
 }
 
 // mergeContext merges the provided Transaction Context into the current one.
+// It must be called with txn.mu held.
 func (txn *Txn) mergeContext(src *api.TxnContext) error {
 	if src == nil {
 		return nil
@@ -274,12 +396,19 @@ func (txn *Txn) mergeContext(src *api.TxnContext) error {
 	return nil
 }
 
-func (txn *Txn) commitOrAbort(ctx context.Context) error {
+// commitOrAbort must be called with txn.reqMu held for writing.
+func (txn *Txn) commitOrAbort(ctx context.Context, abort bool) error {
+	txn.mu.Lock()
+	if abort {
+		txn.context.Aborted = true
+	}
 	if txn.finished {
+		txn.mu.Unlock()
 		return nil
 	}
 	txn.finished = true
 	if !txn.mutated {
+		txn.mu.Unlock()
 		return nil
 	}
 
@@ -292,9 +421,18 @@ func (txn *Txn) commitOrAbort(ctx context.Context) error {
 	for pred := range txn.preds {
 		txn.context.Preds = append(txn.context.Preds, pred)
 	}
+	txn.mu.Unlock()
+
+	commitOrAbort := func(ctx context.Context, attempt int) error {
+		call := &Call{Method: "CommitOrAbort", Request: txn.context, Txn: txn, Attempt: attempt}
+		_, err := intercept(ctx, txn.dg, call, func(ctx context.Context) (*api.TxnContext, error) {
+			return txn.dc.CommitOrAbort(ctx, txn.context)
+		})
+		return err
+	}
 
 	ctx = txn.dg.getContext(ctx)
-	_, err := txn.dc.CommitOrAbort(ctx, txn.context)
+	err := commitOrAbort(ctx, 1)
 
 	if isJwtExpired(err) {
 		err = txn.dg.retryLogin(ctx)
@@ -303,7 +441,10 @@ func (txn *Txn) commitOrAbort(ctx context.Context) error {
 		}
 
 		ctx = txn.dg.getContext(ctx)
-		_, err = txn.dc.CommitOrAbort(ctx, txn.context)
+		err = commitOrAbort(ctx, 2)
+	}
+	if err == nil && !abort && txn.dg.cache != nil {
+		txn.dg.cache.invalidate(txn.context.Preds)
 	}
 
 	return err
@@ -7,9 +7,16 @@ package dgo_test
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgo/v250"
+	"github.com/dgraph-io/dgo/v250/protos/api"
 )
 
 func TestQueryNoDiscardTxn(t *testing.T) {
@@ -26,3 +33,199 @@ func TestQueryNoDiscardTxn(t *testing.T) {
 	require.NoError(t, err)
 	require.GreaterOrEqual(t, len(resp.GetHdrs()), 1)
 }
+
+func TestReadOnlyTxnAt(t *testing.T) {
+	dg, cancel := getDgraphClient()
+	defer cancel()
+
+	ctx := context.Background()
+	require.NoError(t, dg.DropAll(ctx))
+	require.NoError(t, dg.SetSchema(ctx, `name: string @index(exact) .`))
+
+	_, err := dg.NewTxn().Mutate(ctx, &api.Mutation{
+		SetNquads: []byte(`_:a <name> "Alice" .`),
+		CommitNow: true,
+	})
+	require.NoError(t, err)
+
+	txn := dg.NewReadOnlyTxn()
+	_, err = txn.Query(ctx, `{ q(func: has(name)) { name } }`)
+	require.NoError(t, err)
+	startTs := txn.StartTs()
+	require.NotZero(t, startTs)
+
+	_, err = dg.NewTxn().Mutate(ctx, &api.Mutation{
+		SetNquads: []byte(`_:b <name> "Bob" .`),
+		CommitNow: true,
+	})
+	require.NoError(t, err)
+
+	resp, err := dg.NewReadOnlyTxnAt(startTs).Query(ctx, `{ q(func: has(name)) { name } }`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"q": [{"name": "Alice"}]}`, string(resp.Json))
+	require.Equal(t, startTs, resp.Txn.StartTs)
+}
+
+func TestReadOnlyTxnAtOffline(t *testing.T) {
+	var reqs []*api.Request
+	fake := &fakeDgraphClient{query: func(_ context.Context, req *api.Request) (*api.Response, error) {
+		reqs = append(reqs, req)
+		return &api.Response{Json: []byte(`{}`), Txn: &api.TxnContext{StartTs: req.StartTs}}, nil
+	}}
+	dg := dgo.NewDgraphClient(fake)
+
+	txn := dg.NewReadOnlyTxnAt(100)
+	require.Equal(t, uint64(100), txn.StartTs())
+	_, err := txn.Query(context.Background(), `{ q(func: uid(1)) { uid } }`)
+	require.NoError(t, err)
+	_, err = txn.Mutate(context.Background(), &api.Mutation{SetNquads: []byte(`_:a <name> "A" .`)})
+	require.ErrorIs(t, err, dgo.ErrReadOnly)
+
+	require.Len(t, reqs, 1)
+	require.Equal(t, uint64(100), reqs[0].StartTs)
+	require.True(t, reqs[0].ReadOnly)
+}
+
+func TestTxnConcurrentOffline(t *testing.T) {
+	const startTs = 7
+
+	var mu sync.Mutex
+	var reqs []*api.Request
+	var commits []*api.TxnContext
+	fake := &fakeDgraphClient{
+		query: func(_ context.Context, req *api.Request) (*api.Response, error) {
+			mu.Lock()
+			reqs = append(reqs, req)
+			mu.Unlock()
+
+			// Give the other goroutines a chance to run while the request is in flight.
+			time.Sleep(time.Millisecond)
+			tc := &api.TxnContext{StartTs: startTs}
+			if req.StartTs != 0 {
+				tc.StartTs = req.StartTs
+			}
+			for _, mu := range req.Mutations {
+				tc.Keys = append(tc.Keys, string(mu.SetNquads))
+				tc.Preds = append(tc.Preds, "name")
+			}
+			return &api.Response{Json: []byte(`{}`), Txn: tc}, nil
+		},
+		commitOrAbort: func(_ context.Context, tc *api.TxnContext) (*api.TxnContext, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			commits = append(commits, tc)
+			return tc, nil
+		},
+	}
+	dg := dgo.NewDgraphClient(fake)
+	txn := dg.NewTxn()
+	ctx := context.Background()
+
+	const workers = 20
+	var wg sync.WaitGroup
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i%2 == 0 {
+				_, err := txn.Query(ctx, `{ q(func: has(name)) { uid } }`)
+				require.NoError(t, err)
+				return
+			}
+			_, err := txn.Mutate(ctx, &api.Mutation{SetNquads: []byte(fmt.Sprintf(`_:n%d <name> "n" .`, i))})
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, txn.Commit(ctx))
+
+	require.Len(t, reqs, workers)
+	startTsCount := 0
+	for _, req := range reqs {
+		if req.StartTs == 0 {
+			startTsCount++
+			continue
+		}
+		require.Equal(t, uint64(startTs), req.StartTs)
+	}
+	require.Equal(t, 1, startTsCount, "only the first request should be sent without a start ts")
+
+	require.Len(t, commits, 1)
+	require.Len(t, commits[0].Keys, workers/2)
+	require.Equal(t, []string{"name"}, commits[0].Preds)
+	require.Equal(t, uint64(startTs), commits[0].StartTs)
+
+	_, err := txn.Query(ctx, `{ q(func: has(name)) { uid } }`)
+	require.ErrorIs(t, err, dgo.ErrFinished)
+}
+
+func TestTxnCommitWaitsForInflightOffline(t *testing.T) {
+	inflight := make(chan struct{})
+	release := make(chan struct{})
+	var committed atomic.Bool
+	fake := &fakeDgraphClient{
+		query: func(_ context.Context, req *api.Request) (*api.Response, error) {
+			close(inflight)
+			<-release
+			require.False(t, committed.Load(), "commit must wait for in-flight requests")
+			return &api.Response{Txn: &api.TxnContext{StartTs: 1, Keys: []string{"k"}}}, nil
+		},
+		commitOrAbort: func(_ context.Context, tc *api.TxnContext) (*api.TxnContext, error) {
+			committed.Store(true)
+			require.Equal(t, []string{"k"}, tc.Keys)
+			return tc, nil
+		},
+	}
+	dg := dgo.NewDgraphClient(fake)
+	txn := dg.NewTxn()
+	ctx := context.Background()
+
+	done := make(chan error)
+	go func() {
+		_, err := txn.Mutate(ctx, &api.Mutation{SetNquads: []byte(`_:a <name> "A" .`)})
+		done <- err
+	}()
+	<-inflight
+
+	commitDone := make(chan error)
+	go func() { commitDone <- txn.Commit(ctx) }()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	require.NoError(t, <-done)
+	require.NoError(t, <-commitDone)
+	require.True(t, committed.Load())
+}
+
+func TestTxnConcurrent(t *testing.T) {
+	dg, cancel := getDgraphClient()
+	defer cancel()
+
+	ctx := context.Background()
+	require.NoError(t, dg.DropAll(ctx))
+	require.NoError(t, dg.SetSchema(ctx, `name: string @index(exact) .`))
+
+	txn := dg.NewTxn()
+	defer func() { require.NoError(t, txn.Discard(ctx)) }()
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := txn.Mutate(ctx, &api.Mutation{SetNquads: []byte(fmt.Sprintf(`_:n <name> "n%d" .`, i))})
+			require.NoError(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := txn.Query(ctx, `{ q(func: has(name)) { name } }`)
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, txn.Commit(ctx))
+
+	resp, err := dg.NewReadOnlyTxn().Query(ctx, `{ q(func: has(name)) { count(uid) } }`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"q": [{"count": 10}]}`, string(resp.Json))
+}
@@ -38,9 +38,10 @@ func (d *Dgraph) allocateIDs(ctx context.Context, howMany uint64,
 	leaseType api.LeaseType) (uint64, uint64, error) {
 
 	req := &api.AllocateIDsRequest{HowMany: howMany, LeaseType: leaseType}
-	resp, err := doWithRetryLogin(ctx, d, func(dc api.DgraphClient) (*api.AllocateIDsResponse, error) {
-		return dc.AllocateIDs(d.getContext(ctx), req)
-	})
+	resp, err := doWithRetryLogin(ctx, d, "AllocateIDs", req,
+		func(ctx context.Context, dc api.DgraphClient) (*api.AllocateIDsResponse, error) {
+			return dc.AllocateIDs(ctx, req)
+		})
 	if err != nil {
 		return 0, 0, err
 	}